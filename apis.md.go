@@ -2,6 +2,8 @@
 
 package workwx
 
+import "context"
+
 // execGetAccessToken 获取access_token
 func (c *WorkwxApp) execGetAccessToken(req reqAccessToken) (respAccessToken, error) {
 	var resp respAccessToken
@@ -57,6 +59,17 @@ func (c *WorkwxApp) execAuthCode2UserInfo(req reqAuthCode2UserInfo) (respAuthCod
 	return resp, nil
 }
 
+// execGetCallbackIP 获取企业微信回调IP段
+func (c *WorkwxApp) execGetCallbackIP(req reqGetCallbackIP) (respGetCallbackIP, error) {
+	var resp respGetCallbackIP
+	err := executeQyapiGet(c, "/cgi-bin/getcallbackip", req, &resp, true)
+	if err != nil {
+		return respGetCallbackIP{}, err
+	}
+
+	return resp, nil
+}
+
 // execUserGet 读取成员
 func (c *WorkwxApp) execUserGet(req reqUserGet) (respUserGet, error) {
 	var resp respUserGet
@@ -366,9 +379,9 @@ func (c *WorkwxApp) execAppchatGet(req reqAppchatGet) (respAppchatGet, error) {
 }
 
 // execMessageSend 发送应用消息
-func (c *WorkwxApp) execMessageSend(req reqMessage) (respMessageSend, error) {
+func (c *WorkwxApp) execMessageSend(ctx context.Context, req reqMessage) (respMessageSend, error) {
 	var resp respMessageSend
-	err := executeQyapiJSONPost(c, "/cgi-bin/message/send", req, &resp, true)
+	err := executeQyapiJSONPostWithContext(ctx, c, "/cgi-bin/message/send", req, &resp, true)
 	if err != nil {
 		return respMessageSend{}, err
 	}
@@ -377,9 +390,9 @@ func (c *WorkwxApp) execMessageSend(req reqMessage) (respMessageSend, error) {
 }
 
 // execAppchatSend 应用推送消息
-func (c *WorkwxApp) execAppchatSend(req reqMessage) (respMessageSend, error) {
+func (c *WorkwxApp) execAppchatSend(ctx context.Context, req reqMessage) (respMessageSend, error) {
 	var resp respMessageSend
-	err := executeQyapiJSONPost(c, "/cgi-bin/appchat/send", req, &resp, true)
+	err := executeQyapiJSONPostWithContext(ctx, c, "/cgi-bin/appchat/send", req, &resp, true)
 	if err != nil {
 		return respMessageSend{}, err
 	}
@@ -387,6 +400,28 @@ func (c *WorkwxApp) execAppchatSend(req reqMessage) (respMessageSend, error) {
 	return resp, nil
 }
 
+// execMessageUpdateTemplateCard 更新模版卡片消息
+func (c *WorkwxApp) execMessageUpdateTemplateCard(req reqMessageUpdateTemplateCard) (respMessageUpdateTemplateCard, error) {
+	var resp respMessageUpdateTemplateCard
+	err := executeQyapiJSONPost(c, "/cgi-bin/message/update_template_card", req, &resp, true)
+	if err != nil {
+		return respMessageUpdateTemplateCard{}, err
+	}
+
+	return resp, nil
+}
+
+// execMessageRecall 撤回应用消息
+func (c *WorkwxApp) execMessageRecall(req reqMessageRecall) (respMessageRecall, error) {
+	var resp respMessageRecall
+	err := executeQyapiJSONPost(c, "/cgi-bin/message/recall", req, &resp, true)
+	if err != nil {
+		return respMessageRecall{}, err
+	}
+
+	return resp, nil
+}
+
 // execMediaUpload 上传临时素材
 func (c *WorkwxApp) execMediaUpload(req reqMediaUpload) (respMediaUpload, error) {
 	var resp respMediaUpload
@@ -872,9 +907,9 @@ func (c *WorkwxApp) execKfSyncMsg(req reqKfSyncMsg) (respKfSyncMsg, error) {
 }
 
 // execKfSend 发送消息
-func (c *WorkwxApp) execKfSend(req reqMessage) (respMessageSend, error) {
+func (c *WorkwxApp) execKfSend(ctx context.Context, req reqMessage) (respMessageSend, error) {
 	var resp respMessageSend
-	err := executeQyapiJSONPost(c, "/cgi-bin/kf/send_msg", req, &resp, true)
+	err := executeQyapiJSONPostWithContext(ctx, c, "/cgi-bin/kf/send_msg", req, &resp, true)
 	if err != nil {
 		return respMessageSend{}, err
 	}
@@ -883,9 +918,9 @@ func (c *WorkwxApp) execKfSend(req reqMessage) (respMessageSend, error) {
 }
 
 // execKfOnEventSend 发送欢迎语等事件响应消息
-func (c *WorkwxApp) execKfOnEventSend(req reqMessage) (respMessageSend, error) {
+func (c *WorkwxApp) execKfOnEventSend(ctx context.Context, req reqMessage) (respMessageSend, error) {
 	var resp respMessageSend
-	err := executeQyapiJSONPost(c, "/cgi-bin/kf/send_msg_on_event", req, &resp, true)
+	err := executeQyapiJSONPostWithContext(ctx, c, "/cgi-bin/kf/send_msg_on_event", req, &resp, true)
 	if err != nil {
 		return respMessageSend{}, err
 	}
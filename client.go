@@ -2,9 +2,11 @@ package workwx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"mime/multipart"
+	"net/http"
 	"net/url"
 )
 
@@ -141,6 +143,17 @@ func executeQyapiJSONPost[T bodyer, U tryIntoErr](
 	req T,
 	respObj U,
 	withAccessToken bool,
+) error {
+	return executeQyapiJSONPostWithContext(context.Background(), c, path, req, respObj, withAccessToken)
+}
+
+func executeQyapiJSONPostWithContext[T bodyer, U tryIntoErr](
+	ctx context.Context,
+	c *WorkwxApp,
+	path string,
+	req T,
+	respObj U,
+	withAccessToken bool,
 ) error {
 	url, err := c.composeQyapiURLWithToken(path, req, withAccessToken)
 	if err != nil {
@@ -153,7 +166,13 @@ func executeQyapiJSONPost[T bodyer, U tryIntoErr](
 		return makeReqMarshalErr(err)
 	}
 
-	resp, err := c.opts.HTTP.Post(urlStr, "application/json", bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, bytes.NewReader(body))
+	if err != nil {
+		return makeRequestErr(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.opts.HTTP.Do(httpReq)
 	if err != nil {
 		return makeRequestErr(err)
 	}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/EnxZhou/go-workwx"
+	"github.com/EnxZhou/go-workwx/workwxtest"
+)
+
+func cmdCallbackSimulate(c *cli.Context) error {
+	if c.Bool(flagListKinds) {
+		for _, kind := range workwxtest.SupportedEventKinds() {
+			fmt.Println(kind)
+		}
+		return nil
+	}
+
+	cfg := mustGetConfig(c)
+	endpoint := c.Args().Get(0)
+	if endpoint == "" {
+		return errors.New("callback URL must be given")
+	}
+
+	sim, err := workwxtest.NewSimulator(
+		c.String(flagCallbackToken),
+		c.String(flagCallbackEncodingAESKey),
+		cfg.CorpID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 与企业微信一样，总是先进行回调 URL 验证
+	err = sim.EchoTest(c.Context, endpoint)
+	if err != nil {
+		fmt.Printf("echo test failed: %+v\n", err)
+		return err
+	}
+	fmt.Println("echo test ok")
+
+	if c.Bool(flagEchoTestOnly) {
+		return nil
+	}
+
+	e, err := workwxtest.NewEvent(
+		workwx.MessageType(c.String(flagMessageType)),
+		workwx.EventType(c.String(flagEvent)),
+		workwx.ChangeType(c.String(flagChangeType)),
+	)
+	if err != nil {
+		fmt.Println("use --list to see all supported kinds")
+		return err
+	}
+	e.AgentID = cfg.AgentID
+	if fromUser := c.String(flagFromUser); fromUser != "" {
+		e.FromUserName = fromUser
+	}
+	for _, kv := range c.StringSlice(flagField) {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("malformed field %q, expecting NAME=VALUE", kv)
+		}
+		e.Set(name, value)
+	}
+
+	fmt.Printf("event = %s\n", e.XML())
+
+	result, err := sim.Deliver(c.Context, endpoint, e)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+
+	fmt.Printf("status = %d\n", result.StatusCode)
+	if result.Reply != nil {
+		fmt.Printf("reply = %s\n", result.Reply)
+	} else if len(result.Body) > 0 {
+		fmt.Printf("body = %s\n", result.Body)
+	}
+
+	return nil
+}
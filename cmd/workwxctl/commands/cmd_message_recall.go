@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+func cmdMessageRecall(c *cli.Context) error {
+	cfg := mustGetConfig(c)
+	msgID := c.Args().Get(0)
+	if msgID == "" {
+		return errors.New("no message ID given")
+	}
+
+	app := cfg.MakeWorkwxApp()
+	err := app.RecallMessage(msgID)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+	}
+
+	return err
+}
@@ -10,7 +10,12 @@ import (
 
 func cmdSendMessage(c *cli.Context) error {
 	cfg := mustGetConfig(c)
-	isSafe := c.Bool(flagSafe)
+	opts := workwx.SendOptions{
+		Safe:                   c.Bool(flagSafe),
+		EnableIDTrans:          c.Bool(flagEnableIDTrans),
+		EnableDuplicateCheck:   c.Bool(flagDuplicateCheck),
+		DuplicateCheckInterval: c.Duration(flagDuplicateCheckInterval),
+	}
 	toUsers := c.StringSlice(flagToUser)
 	toParties := c.StringSlice(flagToParty)
 	toTags := c.StringSlice(flagToTag)
@@ -43,35 +48,36 @@ func cmdSendMessage(c *cli.Context) error {
 		msgtype = string(workwx.MessageTypeText)
 	}
 
+	var result *workwx.SendResult
 	var err error
 	switch msgtype {
 	case string(workwx.MessageTypeText):
-		err = app.SendTextMessage(&recipient, content, isSafe)
+		result, err = app.SendTextMessageWithResult(&recipient, content, &opts)
 	case string(workwx.MessageTypeImage):
-		err = app.SendImageMessage(&recipient, mediaID, isSafe)
+		result, err = app.SendImageMessageWithResult(&recipient, mediaID, &opts)
 	case string(workwx.MessageTypeVoice):
-		err = app.SendVoiceMessage(&recipient, mediaID, isSafe)
+		result, err = app.SendVoiceMessageWithResult(&recipient, mediaID, &opts)
 	case string(workwx.MessageTypeVideo):
-		err = app.SendVideoMessage(
+		result, err = app.SendVideoMessageWithResult(
 			&recipient,
 			mediaID,
 			description,
 			title,
-			isSafe,
+			&opts,
 		)
 	case "file":
-		err = app.SendFileMessage(&recipient, mediaID, isSafe)
+		result, err = app.SendFileMessageWithResult(&recipient, mediaID, &opts)
 	case "textcard":
-		err = app.SendTextCardMessage(
+		result, err = app.SendTextCardMessageWithResult(
 			&recipient,
 			title,
 			description,
 			url,
 			buttonText,
-			isSafe,
+			&opts,
 		)
 	case "news":
-		err = app.SendNewsMessage(
+		result, err = app.SendNewsMessageWithResult(
 			&recipient,
 			[]workwx.Article{
 				workwx.Article{
@@ -83,10 +89,10 @@ func cmdSendMessage(c *cli.Context) error {
 					PagePath:    "",
 				},
 			},
-			isSafe,
+			&opts,
 		)
 	case "mpnews":
-		err = app.SendMPNewsMessage(
+		result, err = app.SendMPNewsMessageWithResult(
 			&recipient,
 			[]workwx.MPArticle{workwx.MPArticle{
 				Title:            title,
@@ -96,12 +102,19 @@ func cmdSendMessage(c *cli.Context) error {
 				Content:          content,
 				Digest:           digest,
 			}},
-			isSafe,
+			&opts,
 		)
 	default:
 		fmt.Printf("unrecognized message type: %s\n", msgtype)
 		panic("unrecognized message type")
 	}
 
+	if result != nil {
+		fmt.Printf("msgid = %s\n", result.MsgID)
+	}
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+	}
+
 	return err
 }
@@ -121,6 +121,18 @@ func InitApp() *cli.App {
 						Name:  flagSafe,
 						Usage: "作为保密消息发送",
 					},
+					&cli.BoolFlag{
+						Name:  flagEnableIDTrans,
+						Usage: "开启 id 转译",
+					},
+					&cli.BoolFlag{
+						Name:  flagDuplicateCheck,
+						Usage: "开启重复消息检查",
+					},
+					&cli.DurationFlag{
+						Name:  flagDuplicateCheckInterval,
+						Usage: "重复消息检查的时间间隔，最长 4h，不指定则为 30m；须同时开启重复消息检查",
+					},
 
 					// 发消息参数
 					&cli.StringFlag{
@@ -165,6 +177,12 @@ func InitApp() *cli.App {
 					},
 				},
 			},
+			{
+				Name:      "message-recall",
+				Usage:     "撤回 24 小时内发送的应用消息",
+				ArgsUsage: "MSGID",
+				Action:    cmdMessageRecall,
+			},
 			{
 				Name:   "upload-temp-media",
 				Usage:  "上传临时素材",
@@ -197,6 +215,53 @@ func InitApp() *cli.App {
 					},
 				},
 			},
+			{
+				Name:      "callback-simulate",
+				Usage:     "模拟企业微信向本地回调 URL 推送事件",
+				ArgsUsage: "URL",
+				Action:    cmdCallbackSimulate,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagCallbackToken,
+						Usage:   "回调配置的 `TOKEN`",
+						EnvVars: []string{"WORKWXCTL_CALLBACK_TOKEN"},
+					},
+					&cli.StringFlag{
+						Name:    flagCallbackEncodingAESKey,
+						Usage:   "回调配置的 `KEY` (EncodingAESKey)",
+						EnvVars: []string{"WORKWXCTL_CALLBACK_ENCODING_AES_KEY"},
+					},
+					&cli.StringFlag{
+						Name:  flagMessageType,
+						Usage: "推送消息的类型: text, image, voice, video, location, link, event",
+						Value: "event",
+					},
+					&cli.StringFlag{
+						Name:  flagEvent,
+						Usage: "推送事件的类型，如 click、change_contact",
+					},
+					&cli.StringFlag{
+						Name:  flagChangeType,
+						Usage: "推送事件的变更类型，如 create_user",
+					},
+					&cli.StringFlag{
+						Name:  flagFromUser,
+						Usage: "覆盖发送者 UserID",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagField,
+						Usage: "以 `NAME=VALUE` 覆盖消息体字段 (可指定多次)",
+					},
+					&cli.BoolFlag{
+						Name:  flagEchoTestOnly,
+						Usage: "只进行回调 URL 验证，不推送事件",
+					},
+					&cli.BoolFlag{
+						Name:  flagListKinds,
+						Usage: "列出所有支持模拟的消息、事件类型",
+					},
+				},
+			},
 		},
 	}
 }
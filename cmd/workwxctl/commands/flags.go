@@ -34,6 +34,10 @@ const (
 	flagToChat       = "to-chat"
 	flagToChatShort  = "c"
 
+	flagEnableIDTrans          = "enable-id-trans"
+	flagDuplicateCheck         = "duplicate-check"
+	flagDuplicateCheckInterval = "duplicate-check-interval"
+
 	flagMediaID          = "media-id"
 	flagThumbMediaID     = "thumb-media-id"
 	flagDescription      = "desc"
@@ -50,6 +54,15 @@ const (
 	flagMentionUser        = "mention-user"
 	flagMentionMobile      = "mention-mobile"
 	flagMentionMobileShort = "m"
+
+	flagCallbackToken          = "token"
+	flagCallbackEncodingAESKey = "encoding-aes-key"
+	flagEvent                  = "event"
+	flagChangeType             = "change-type"
+	flagFromUser               = "from-user"
+	flagField                  = "field"
+	flagEchoTestOnly           = "echo-test-only"
+	flagListKinds              = "list"
 )
 
 type cliOptions struct {
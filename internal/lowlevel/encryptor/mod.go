@@ -40,6 +40,7 @@ func (o *customEntropySource) applyTo(x *WorkwxEncryptor) {
 }
 
 var errMalformedEncodingAESKey = errors.New("malformed EncodingAESKey")
+var errMalformedCiphertext = errors.New("malformed ciphertext")
 var errPayloadTooBig = errors.New("payload too big")
 
 func NewWorkwxEncryptor(
@@ -75,6 +76,9 @@ func (e *WorkwxEncryptor) Decrypt(base64Msg []byte) (WorkwxPayload, error) {
 		return WorkwxPayload{}, err
 	}
 	buf = buf[:n]
+	if len(buf) == 0 || len(buf)%aes.BlockSize != 0 {
+		return WorkwxPayload{}, errMalformedCiphertext
+	}
 
 	// init cipher
 	block, err := aes.NewCipher(e.aesKey)
@@ -87,11 +91,21 @@ func (e *WorkwxEncryptor) Decrypt(base64Msg []byte) (WorkwxPayload, error) {
 
 	// decrypt in-place in the allocated temp buffer
 	state.CryptBlocks(buf, buf)
-	buf = pkcs7.Unpad(buf)
+	buf, err = pkcs7.Unpad(buf)
+	if err != nil {
+		// most likely decrypted with the wrong key
+		return WorkwxPayload{}, err
+	}
 
 	// assemble decrypted payload
 	// drop the 16-byte random prefix
-	msglen := binary.BigEndian.Uint32(buf[16:20])
+	if len(buf) < 20 {
+		return WorkwxPayload{}, errMalformedCiphertext
+	}
+	msglen := uint64(binary.BigEndian.Uint32(buf[16:20]))
+	if msglen > uint64(len(buf)-20) {
+		return WorkwxPayload{}, errMalformedCiphertext
+	}
 	msg := buf[20 : 20+msglen]
 	receiveID := buf[20+msglen:]
 
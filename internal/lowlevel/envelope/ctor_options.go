@@ -2,6 +2,7 @@ package envelope
 
 import (
 	"io"
+	"time"
 )
 
 type ProcessorOption interface {
@@ -31,3 +32,46 @@ func WithTimeSource(t TimeSource) ProcessorOption {
 func (o *customTimeSource) applyTo(x *Processor) {
 	x.timeSource = o.inner
 }
+
+type customReplayWindow struct {
+	inner time.Duration
+}
+
+// WithReplayWindow 拒绝 timestamp 参数与当前时间相差超过 window 的请求
+//
+// 当前时间取自 TimeSource。window 为零（默认）则不检查。
+func WithReplayWindow(window time.Duration) ProcessorOption {
+	return &customReplayWindow{inner: window}
+}
+
+func (o *customReplayWindow) applyTo(x *Processor) {
+	x.replayWindow = o.inner
+}
+
+type customNonceCache struct {
+	inner NonceCache
+}
+
+// WithNonceCache 拒绝在时间窗口内重复出现的 timestamp + nonce
+//
+// 仅在同时设置了 WithReplayWindow 时生效。
+func WithNonceCache(c NonceCache) ProcessorOption {
+	return &customNonceCache{inner: c}
+}
+
+func (o *customNonceCache) applyTo(x *Processor) {
+	x.nonceCache = o.inner
+}
+
+type customReplayRejectHook struct {
+	inner func(err error)
+}
+
+// WithReplayRejectHook 请求因疑似重放被拒绝时调用 hook
+func WithReplayRejectHook(hook func(err error)) ProcessorOption {
+	return &customReplayRejectHook{inner: hook}
+}
+
+func (o *customReplayRejectHook) applyTo(x *Processor) {
+	x.replayRejectHook = o.inner
+}
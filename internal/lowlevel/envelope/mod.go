@@ -4,10 +4,12 @@ import (
 	"crypto/rand"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/encryptor"
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/signature"
@@ -18,6 +20,10 @@ type Processor struct {
 	encryptor     *encryptor.WorkwxEncryptor
 	entropySource io.Reader
 	timeSource    TimeSource
+
+	replayWindow     time.Duration
+	nonceCache       NonceCache
+	replayRejectHook func(err error)
 }
 
 func NewProcessor(
@@ -30,6 +36,10 @@ func NewProcessor(
 		encryptor:     nil, // XXX init later
 		entropySource: rand.Reader,
 		timeSource:    DefaultTimeSource{},
+
+		replayWindow:     0,
+		nonceCache:       nil,
+		replayRejectHook: nil,
 	}
 	for _, o := range opts {
 		o.applyTo(&obj)
@@ -47,7 +57,14 @@ func NewProcessor(
 	return &obj, nil
 }
 
-var errInvalidSignature = errors.New("invalid signature")
+// ErrInvalidSignature 请求签名校验失败
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrMalformedEnvelope 请求体不是合法的回调包
+var ErrMalformedEnvelope = errors.New("malformed envelope")
+
+// ErrDecryptFailed 签名正确但解密失败，多半是 EncodingAESKey 不对
+var ErrDecryptFailed = errors.New("decrypt failed")
 
 func (p *Processor) HandleIncomingMsg(
 	url *url.URL,
@@ -57,16 +74,24 @@ func (p *Processor) HandleIncomingMsg(
 	var x xmlRxEnvelope
 	err := xml.Unmarshal(body, &x)
 	if err != nil {
-		return Envelope{}, err
+		return Envelope{}, fmt.Errorf("%w: %w", ErrMalformedEnvelope, err)
 	}
 
 	// check signature
 	if !signature.VerifyHTTPRequestSignature(p.token, url, x.Encrypt) {
-		return Envelope{}, errInvalidSignature
+		return Envelope{}, ErrInvalidSignature
 	}
 
 	// decrypt message
 	msg, err := p.encryptor.Decrypt([]byte(x.Encrypt))
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: %w", ErrDecryptFailed, err)
+	}
+
+	// check freshness
+	// done after decryption, so nonces are only remembered for requests that
+	// are really ours
+	err = p.checkReplay(url)
 	if err != nil {
 		return Envelope{}, err
 	}
@@ -81,9 +106,19 @@ func (p *Processor) HandleIncomingMsg(
 }
 
 func (p *Processor) MakeOutgoingEnvelope(msg []byte) ([]byte, error) {
+	return p.MakeOutgoingEnvelopeWithReceiveID(msg, nil)
+}
+
+// MakeOutgoingEnvelopeWithReceiveID 组装被动响应包，加密内容中携带给定的 ReceiveID
+//
+// 被动回复时应带上收到的回调包中的 ReceiveID（一般为 CorpID）。
+func (p *Processor) MakeOutgoingEnvelopeWithReceiveID(
+	msg []byte,
+	receiveID []byte,
+) ([]byte, error) {
 	workwxPayload := encryptor.WorkwxPayload{
 		Msg:       msg,
-		ReceiveID: nil,
+		ReceiveID: receiveID,
 	}
 	encryptedMsg, err := p.encryptor.Encrypt(&workwxPayload)
 	if err != nil {
@@ -2,9 +2,11 @@ package envelope
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/url"
 	"testing"
+	"time"
 
 	c "github.com/smartystreets/goconvey/convey"
 )
@@ -46,13 +48,17 @@ func TestProcessor(t *testing.T) {
 		var outDeserialized struct {
 			Encrypt      string `xml:"Encrypt"`
 			MsgSignature string `xml:"MsgSignature"`
-			Timestamp    int64  `xml:"Timestamp"`
+			Timestamp    int64  `xml:"TimeStamp"`
 			Nonce        string `xml:"Nonce"`
 		}
 
 		err = xml.Unmarshal(out, &outDeserialized)
 		c.So(err, c.ShouldBeNil)
 
+		// 企业微信要求的元素名是 TimeStamp，大小写不对会导致验签失败
+		c.So(string(out), c.ShouldContainSubstring, fmt.Sprintf("<TimeStamp>%d</TimeStamp>", outDeserialized.Timestamp))
+		c.So(outDeserialized.Timestamp, c.ShouldNotEqual, 0)
+
 		// 做一个假的 URL
 		s := fmt.Sprintf(
 			"http://a.b/?msg_signature=%s&timestamp=%d&nonce=%s",
@@ -71,3 +77,80 @@ func TestProcessor(t *testing.T) {
 		c.So(rtt.ReceiveID, c.ShouldBeEmpty)
 	})
 }
+
+type fixedTimeSource struct {
+	t time.Time
+}
+
+func (x fixedTimeSource) GetCurrentTimestamp() time.Time {
+	return x.t
+}
+
+type mapNonceCache map[string]struct{}
+
+func (c mapNonceCache) MarkSeen(key string, _ time.Duration) (bool, error) {
+	_, ok := c[key]
+	c[key] = struct{}{}
+	return ok, nil
+}
+
+func (c mapNonceCache) Forget(key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestProcessorReplayProtection(t *testing.T) {
+	//nolint: gosec  // randomly generated for test purposes only
+	token := "kz7Yx62CH8SaLN"
+	encodingAESKey := "cD0d7jx4tYvVtzqrmh3Dm3QFCXe6f8SlHoMtMh3qQEP"
+	s := "http://test.example.com/?msg_signature=f265ae551b1932727204c3d707628d01376a6940&timestamp=1583995625&nonce=1584392382"
+	body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><Encrypt><![CDATA[EUCt7xMcNiyASzZj0Hjc5yDjFQrCum6AfQ3ntHiUzjGQ51xieKmbvtrZ40/EcB2W/W8yH0n4Lqx48gJl/T9HD/R309I0P/r5pIZucK3lyEn48FYMr4YdE0QdL2jIJ3xkcXUr6uzefzCxG6lMvwpAJaOyVCzN7sRRw47njfxy5EIqU6R9ZBhlTzfdnhhOhK/nTwzrZX3SoGlXFA9OBeZ6ru1NWpXFk76x9DUMe0lcxPPiUqK8ctnQcYXSGUHVqC6DfG7E7mab0OmruNN8cBZY5d3dYOBA4OgaH55Q0AJmUpdT8vNiXpXx+6TxT3TIjySXpDrHVyrsb772aYywgg/Nu4kUmGkALwFZlzhjNegR7wDwb9lr4ERXsSSS8JZ8lbBmaQ3F2Tq584xoPj5rIhXAF734ynm4no1g+SdHiNqR328=]]></Encrypt><AgentID><![CDATA[1000002]]></AgentID></xml>")
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	c.Convey("防重放", t, func() {
+		var rejected []error
+		cache := mapNonceCache{}
+		now := time.Unix(1583995625, 0)
+
+		newProcessor := func() *Processor {
+			pr, err := NewProcessor(
+				token,
+				encodingAESKey,
+				WithTimeSource(fixedTimeSource{t: now}),
+				WithReplayWindow(5*time.Minute),
+				WithNonceCache(cache),
+				WithReplayRejectHook(func(err error) { rejected = append(rejected, err) }),
+			)
+			c.So(err, c.ShouldBeNil)
+			return pr
+		}
+
+		c.Convey("窗口内的新请求可以通过，重复的 nonce 被拒绝", func() {
+			pr := newProcessor()
+			_, err := pr.HandleIncomingMsg(u, body)
+			c.So(err, c.ShouldBeNil)
+
+			_, err = pr.HandleIncomingMsg(u, body)
+			c.So(errors.Is(err, ErrReplayDetected), c.ShouldBeTrue)
+			c.So(rejected, c.ShouldHaveLength, 1)
+
+			c.Convey("ReleaseNonce 之后允许重试", func() {
+				pr.ReleaseNonce(u)
+				_, err := pr.HandleIncomingMsg(u, body)
+				c.So(err, c.ShouldBeNil)
+			})
+		})
+
+		c.Convey("超出窗口的请求被拒绝", func() {
+			now = now.Add(6 * time.Minute)
+			pr := newProcessor()
+			_, err := pr.HandleIncomingMsg(u, body)
+			c.So(errors.Is(err, ErrReplayDetected), c.ShouldBeTrue)
+			c.So(rejected, c.ShouldHaveLength, 1)
+			c.So(cache, c.ShouldBeEmpty)
+		})
+	})
+}
@@ -18,7 +18,7 @@ type xmlTxEnvelope struct {
 	XMLName      xml.Name  `xml:"xml"`
 	Encrypt      cdataNode `xml:"Encrypt"`
 	MsgSignature cdataNode `xml:"MsgSignature"`
-	Timestamp    int64     `xml:"Timestamp"`
+	Timestamp    int64     `xml:"TimeStamp"`
 	Nonce        cdataNode `xml:"Nonce"`
 }
 
@@ -27,4 +27,7 @@ type Envelope struct {
 	AgentID    string
 	Msg        []byte
 	ReceiveID  []byte
+
+	// CredentialID 解开本消息所用凭据的标识，由 httpapi 填充
+	CredentialID string
 }
@@ -0,0 +1,82 @@
+package envelope
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NonceCache 记录见过的 nonce，用于拒绝时间窗口内的重放请求
+type NonceCache interface {
+	// MarkSeen 标记 key 已出现，有效期为 ttl；如果有效期内已被标记过则返回 true
+	MarkSeen(key string, ttl time.Duration) (bool, error)
+	// Forget 撤销对 key 的标记
+	Forget(key string) error
+}
+
+// ErrReplayDetected 请求疑似重放
+var ErrReplayDetected = errors.New("replayed request")
+
+var errStaleTimestamp = fmt.Errorf("%w: timestamp outside of freshness window", ErrReplayDetected)
+var errDuplicateNonce = fmt.Errorf("%w: duplicate nonce", ErrReplayDetected)
+var errMalformedTimestamp = errors.New("malformed timestamp")
+
+func nonceCacheKey(u *url.URL) string {
+	q := u.Query()
+	return q.Get("timestamp") + ":" + q.Get("nonce")
+}
+
+// checkReplay 检查请求的 timestamp 与 nonce，签名校验通过之后才能调用
+func (p *Processor) checkReplay(u *url.URL) error {
+	if p.replayWindow <= 0 {
+		return nil
+	}
+
+	ts, err := strconv.ParseInt(u.Query().Get("timestamp"), 10, 64)
+	if err != nil {
+		return errMalformedTimestamp
+	}
+
+	now := p.timeSource.GetCurrentTimestamp()
+	delta := now.Sub(time.Unix(ts, 0))
+	if delta > p.replayWindow || delta < -p.replayWindow {
+		return p.rejectReplay(errStaleTimestamp)
+	}
+
+	if p.nonceCache == nil {
+		return nil
+	}
+
+	// timestamp 可以在 [now - window, now + window] 之间，nonce 至少要记这么久
+	seen, err := p.nonceCache.MarkSeen(nonceCacheKey(u), 2*p.replayWindow)
+	if err != nil {
+		return err
+	}
+	if seen {
+		return p.rejectReplay(errDuplicateNonce)
+	}
+
+	return nil
+}
+
+func (p *Processor) rejectReplay(err error) error {
+	if p.replayRejectHook != nil {
+		p.replayRejectHook(err)
+	}
+
+	return err
+}
+
+// ReleaseNonce 撤销对请求 nonce 的记录
+//
+// 成功解出但处理失败的请求应当调用本方法，否则企业微信携带相同 nonce 的重试会被
+// 当作重放拒绝掉。
+func (p *Processor) ReleaseNonce(u *url.URL) {
+	if p.replayWindow <= 0 || p.nonceCache == nil {
+		return
+	}
+
+	_ = p.nonceCache.Forget(nonceCacheKey(u))
+}
@@ -2,10 +2,13 @@ package httpapi
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/encryptor"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/signature"
 )
 
@@ -78,27 +81,51 @@ func (x URLValuesForEchoTestAPI) ToEchoTestAPIArgs() (EchoTestAPIArgs, error) {
 	}, nil
 }
 
+// MatchesEchoTestSignature 回调 URL 验证请求的签名是否与任一凭据相符
+//
+// 仅验签，不解密，也不触发 RejectHook，供多应用端点挑选应用使用。
+func (h *LowlevelHandler) MatchesEchoTestSignature(r *http.Request) bool {
+	for _, e := range h.credentials() {
+		if signature.VerifyHTTPRequestSignature(e.token, r.URL, "") {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *LowlevelHandler) echoTestHandler(
 	rw http.ResponseWriter,
 	r *http.Request,
 ) {
 	url := r.URL
 
-	if !signature.VerifyHTTPRequestSignature(h.token, url, "") {
-		rw.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
 	adapter := URLValuesForEchoTestAPI(url.Query())
 	args, err := adapter.ToEchoTestAPIArgs()
 	if err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
+		h.reject(rw, r, http.StatusBadRequest, fmt.Errorf("%w: %w", envelope.ErrMalformedEnvelope, err))
 		return
 	}
 
-	payload, err := h.encryptor.Decrypt([]byte(args.EchoStr))
+	// try every credential in turn, for rotations in progress
+	var payload encryptor.WorkwxPayload
+	err = envelope.ErrInvalidSignature
+	for _, e := range h.credentials() {
+		if !signature.VerifyHTTPRequestSignature(e.token, url, "") {
+			continue
+		}
+
+		payload, err = e.encryptor.Decrypt([]byte(args.EchoStr))
+		if err == nil {
+			break
+		}
+		err = fmt.Errorf("%w: %w", envelope.ErrDecryptFailed, err)
+	}
+	if err == nil {
+		err = h.checkReceiveID(payload.ReceiveID)
+	}
+
 	if err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
+		h.reject(rw, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -1,6 +1,8 @@
 package httpapi
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 
@@ -8,7 +10,11 @@ import (
 )
 
 type EnvelopeHandler interface {
-	OnIncomingEnvelope(rx envelope.Envelope) error
+	// OnIncomingEnvelope 处理一条解密后的回调消息
+	//
+	// 返回非空的 reply 表示需要被动回复，内容为明文回复包 XML，会被加密、签名后
+	// 作为 HTTP 响应体返回。
+	OnIncomingEnvelope(ctx context.Context, rx envelope.Envelope) (reply []byte, err error)
 }
 
 func (h *LowlevelHandler) eventHandler(
@@ -18,27 +24,59 @@ func (h *LowlevelHandler) eventHandler(
 	// request bodies are assumed small
 	// we can't do streaming parse/decrypt/verification anyway
 	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
+	var src io.Reader = r.Body
+	if h.maxBodySize > 0 {
+		src = http.MaxBytesReader(rw, r.Body, h.maxBodySize)
+	}
+	body, err := io.ReadAll(src)
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.reject(rw, r, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	// signature verification is inside EnvelopeProcessor
-	ev, err := h.ep.HandleIncomingMsg(r.URL, body)
+	ev, cred, err := h.openEnvelope(r.URL, body)
 	if err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
+		h.reject(rw, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.checkReceiveID(ev.ReceiveID)
+	if err != nil {
+		h.reject(rw, r, http.StatusBadRequest, err)
+		return
+	}
+
+	reply, err := h.eh.OnIncomingEnvelope(r.Context(), ev)
+	if err != nil {
+		// let the retry through
+		cred.ep.ReleaseNonce(r.URL)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if len(reply) == 0 {
+		// nothing to reply with, a bare 200 response is all we need
+		rw.WriteHeader(http.StatusOK)
+		// No way to signal failure with the typical HTTP handler method signature
+		_, _ = rw.Write(h.ackBody)
 		return
 	}
 
-	err = h.eh.OnIncomingEnvelope(ev)
+	// passive reply: encrypt & sign with the same ReceiveID we got
+	out, err := cred.ep.MakeOutgoingEnvelopeWithReceiveID(reply, ev.ReceiveID)
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	// currently we always return empty 200 responses
-	// any reply is to be sent asynchronously
-	// this might change in the future (maybe save a couple of RTT or so)
+	rw.Header().Set("Content-Type", "application/xml; charset=utf-8")
 	rw.WriteHeader(http.StatusOK)
+	// No way to signal failure with the typical HTTP handler method signature
+	_, _ = rw.Write(out)
 }
@@ -1,48 +1,290 @@
 package httpapi
 
 import (
+	"errors"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"sync/atomic"
 
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/encryptor"
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
 )
 
-type LowlevelHandler struct {
+// Credential 一组回调凭据，即应用配置的 Token 与 EncodingAESKey
+type Credential struct {
+	// ID 凭据的标识，用于报告匹配到了哪一组凭据、按标识停用凭据
+	ID             string
+	Token          string
+	EncodingAESKey string
+}
+
+type credentialEntry struct {
+	id        string
 	token     string
 	encryptor *encryptor.WorkwxEncryptor
 	ep        *envelope.Processor
-	eh        EnvelopeHandler
 }
 
+type LowlevelHandler struct {
+	// creds 按优先级排列的凭据，整体替换
+	creds   atomic.Pointer[[]*credentialEntry]
+	credsMu sync.Mutex
+
+	epOpts []envelope.ProcessorOption
+	eh     EnvelopeHandler
+
+	// ackBody EnvelopeHandler 不回复时的响应体
+	ackBody []byte
+
+	// maxBodySize 请求体的最大字节数，非正数表示不限制
+	maxBodySize int64
+	// allowSource 判断请求来源是否可信，为 nil 则不检查
+	allowSource func(r *http.Request) bool
+	// receiveID 期望的 ReceiveID，为空则不检查
+	receiveID string
+	// rejectHook 请求被拒绝时的回调
+	rejectHook func(r *http.Request, err error)
+
+	replayRejected atomic.Uint64
+}
+
+// DefaultMaxBodySize 默认的请求体大小上限
+//
+// 回调包一般只有几 KB，审批等事件也远小于此。
+const DefaultMaxBodySize = 1 << 20
+
+// ErrBodyTooLarge 请求体超出大小上限
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ErrSourceNotAllowed 请求来源不在允许的范围内
+var ErrSourceNotAllowed = errors.New("request source not allowed")
+
+// ErrReceiveIDMismatch 解密所得的 ReceiveID 与期望的不符
+var ErrReceiveIDMismatch = errors.New("receive id mismatch")
+
 var _ http.Handler = (*LowlevelHandler)(nil)
 
+var errNoCredentials = errors.New("at least one credential is required")
+var errCredentialNotFound = errors.New("credential not found")
+
 func NewLowlevelHandler(
 	token string,
 	encodingAESKey string,
 	eh EnvelopeHandler,
+	opts ...envelope.ProcessorOption,
 ) (*LowlevelHandler, error) {
-	enc, err := encryptor.NewWorkwxEncryptor(encodingAESKey)
-	if err != nil {
-		return nil, err
+	creds := []Credential{
+		{
+			ID:             "",
+			Token:          token,
+			EncodingAESKey: encodingAESKey,
+		},
 	}
 
-	ep, err := envelope.NewProcessor(token, encodingAESKey)
+	return NewLowlevelHandlerWithCredentials(creds, eh, opts...)
+}
+
+// NewLowlevelHandlerWithCredentials 构造一个可接受多组凭据的 handler
+//
+// 收到请求时按顺序尝试每一组凭据做验签与解密，用于 Token、EncodingAESKey 轮换
+// 期间兼容仍以旧凭据签名、加密的回调。
+func NewLowlevelHandlerWithCredentials(
+	creds []Credential,
+	eh EnvelopeHandler,
+	opts ...envelope.ProcessorOption,
+) (*LowlevelHandler, error) {
+	h := LowlevelHandler{
+		epOpts:      opts,
+		eh:          eh,
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	err := h.SetCredentials(creds)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LowlevelHandler{
-		token:     token,
-		encryptor: enc,
-		ep:        ep,
-		eh:        eh,
-	}, nil
+	return &h, nil
+}
+
+// SetCredentials 整体替换所用的凭据，立即生效
+func (h *LowlevelHandler) SetCredentials(creds []Credential) error {
+	if len(creds) == 0 {
+		return errNoCredentials
+	}
+
+	entries := make([]*credentialEntry, len(creds))
+	for i, c := range creds {
+		enc, err := encryptor.NewWorkwxEncryptor(c.EncodingAESKey)
+		if err != nil {
+			return err
+		}
+
+		ep, err := envelope.NewProcessor(c.Token, c.EncodingAESKey, h.epOpts...)
+		if err != nil {
+			return err
+		}
+
+		entries[i] = &credentialEntry{
+			id:        c.ID,
+			token:     c.Token,
+			encryptor: enc,
+			ep:        ep,
+		}
+	}
+
+	h.credsMu.Lock()
+	defer h.credsMu.Unlock()
+
+	h.creds.Store(&entries)
+	return nil
+}
+
+// RetireCredential 停用给定标识的凭据，立即生效
+//
+// 不允许停用最后一组凭据。
+func (h *LowlevelHandler) RetireCredential(id string) error {
+	h.credsMu.Lock()
+	defer h.credsMu.Unlock()
+
+	old := *h.creds.Load()
+	entries := make([]*credentialEntry, 0, len(old))
+	for _, e := range old {
+		if e.id != id {
+			entries = append(entries, e)
+		}
+	}
+
+	if len(entries) == len(old) {
+		return errCredentialNotFound
+	}
+	if len(entries) == 0 {
+		return errNoCredentials
+	}
+
+	h.creds.Store(&entries)
+	return nil
+}
+
+func (h *LowlevelHandler) credentials() []*credentialEntry {
+	return *h.creds.Load()
+}
+
+// openEnvelope 依次尝试各组凭据验签、解密回调请求
+func (h *LowlevelHandler) openEnvelope(
+	url *url.URL,
+	body []byte,
+) (envelope.Envelope, *credentialEntry, error) {
+	var firstErr error
+	for _, e := range h.credentials() {
+		ev, err := e.ep.HandleIncomingMsg(url, body)
+		if err == nil {
+			ev.CredentialID = e.id
+			return ev, e, nil
+		}
+
+		if errors.Is(err, envelope.ErrReplayDetected) {
+			// the request is ours, but rejected
+			h.replayRejected.Add(1)
+			return envelope.Envelope{}, nil, err
+		}
+
+		// prefer errors other than signature mismatch, they are more telling
+		if firstErr == nil || errors.Is(firstErr, envelope.ErrInvalidSignature) {
+			firstErr = err
+		}
+	}
+
+	return envelope.Envelope{}, nil, firstErr
+}
+
+// SetAckBody 设置 EnvelopeHandler 不回复时的响应体，默认为空
+//
+// 如第三方应用的指令回调要求响应明文 success。须在开始服务前调用。
+func (h *LowlevelHandler) SetAckBody(body []byte) {
+	h.ackBody = body
+}
+
+// SetMaxBodySize 设置请求体的最大字节数，非正数表示不限制。须在开始服务前调用。
+func (h *LowlevelHandler) SetMaxBodySize(n int64) {
+	h.maxBodySize = n
+}
+
+// MaxBodySize 返回请求体的最大字节数，非正数表示不限制
+func (h *LowlevelHandler) MaxBodySize() int64 {
+	return h.maxBodySize
+}
+
+// SetSourceFilter 设置判断请求来源是否可信的函数，为 nil 则不检查。须在开始服务前调用。
+func (h *LowlevelHandler) SetSourceFilter(allow func(r *http.Request) bool) {
+	h.allowSource = allow
+}
+
+// SetExpectedReceiveID 设置期望的 ReceiveID，为空则不检查。须在开始服务前调用。
+//
+// 企业内部应用的 ReceiveID 为 CorpID，第三方应用的指令回调则为 SuiteID。
+func (h *LowlevelHandler) SetExpectedReceiveID(id string) {
+	h.receiveID = id
+}
+
+// SetRejectHook 设置请求被拒绝时的回调。须在开始服务前调用。
+func (h *LowlevelHandler) SetRejectHook(hook func(r *http.Request, err error)) {
+	h.rejectHook = hook
+}
+
+// reject 报告并以给定状态码拒绝请求
+func (h *LowlevelHandler) reject(rw http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	if h.rejectHook != nil {
+		h.rejectHook(r, err)
+	}
+
+	rw.WriteHeader(statusCode)
+}
+
+// checkReceiveID 检查解密所得的 ReceiveID
+func (h *LowlevelHandler) checkReceiveID(receiveID []byte) error {
+	if h.receiveID != "" && string(receiveID) != h.receiveID {
+		return ErrReceiveIDMismatch
+	}
+
+	return nil
+}
+
+// RemoteAddrIn 返回一个检查请求的 RemoteAddr 是否在给定范围内的来源过滤函数
+func RemoteAddrIn(prefixes []netip.Prefix) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		ap, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err != nil {
+			return false
+		}
+
+		addr := ap.Addr().Unmap()
+		for _, p := range prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ReplayRejectedCount 返回因疑似重放而被拒绝的回调请求总数
+func (h *LowlevelHandler) ReplayRejectedCount() uint64 {
+	return h.replayRejected.Load()
 }
 
 func (h *LowlevelHandler) ServeHTTP(
 	rw http.ResponseWriter,
 	r *http.Request,
 ) {
+	if h.allowSource != nil && !h.allowSource(r) {
+		h.reject(rw, r, http.StatusForbidden, ErrSourceNotAllowed)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		// 测试回调模式请求
@@ -1,12 +1,19 @@
 package httpapi
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
 )
 
 func TestLowlevelHandler(t *testing.T) {
@@ -35,3 +42,143 @@ func TestLowlevelHandler(t *testing.T) {
 		})
 	})
 }
+
+type echoEnvelopeHandler struct{}
+
+func (echoEnvelopeHandler) OnIncomingEnvelope(_ context.Context, rx envelope.Envelope) ([]byte, error) {
+	return append([]byte("re: "), rx.Msg...), nil
+}
+
+// makeSignedRequestURL 从一个出站响应包构造出对应的带签名回调 URL
+func makeSignedRequestURL(base string, pkt []byte) (string, error) {
+	var x struct {
+		MsgSignature string `xml:"MsgSignature"`
+		Timestamp    int64  `xml:"TimeStamp"`
+		Nonce        string `xml:"Nonce"`
+	}
+	err := xml.Unmarshal(pkt, &x)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"%s?msg_signature=%s&timestamp=%d&nonce=%s",
+		base,
+		x.MsgSignature,
+		x.Timestamp,
+		x.Nonce,
+	), nil
+}
+
+func TestLowlevelHandlerPassiveReply(t *testing.T) {
+	c.Convey("被动回复", t, func() {
+		//nolint: gosec  // randomly generated for test purposes only
+		token := "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme"
+		encodingAESKey := "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"
+		handler, err := NewLowlevelHandler(token, encodingAESKey, echoEnvelopeHandler{})
+		c.So(err, c.ShouldBeNil)
+
+		ep, err := envelope.NewProcessor(token, encodingAESKey)
+		c.So(err, c.ShouldBeNil)
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		// 用出站包的格式伪造一个入站请求，反正只有 Encrypt 元素有意义
+		pkt, err := ep.MakeOutgoingEnvelopeWithReceiveID([]byte("ping"), []byte("ww6a112864f8022910"))
+		c.So(err, c.ShouldBeNil)
+		reqURL, err := makeSignedRequestURL(server.URL+"/", pkt)
+		c.So(err, c.ShouldBeNil)
+
+		resp, err := http.DefaultClient.Post(reqURL, "application/xml", bytes.NewReader(pkt))
+		c.So(err, c.ShouldBeNil)
+		defer resp.Body.Close()
+		c.So(resp.StatusCode, c.ShouldEqual, http.StatusOK)
+
+		body, err := io.ReadAll(resp.Body)
+		c.So(err, c.ShouldBeNil)
+
+		// 响应包应该能用同一套凭据解开
+		respURL, err := makeSignedRequestURL("http://a.b/", body)
+		c.So(err, c.ShouldBeNil)
+		u, err := url.Parse(respURL)
+		c.So(err, c.ShouldBeNil)
+		rtt, err := ep.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldBeNil)
+		c.So(rtt.Msg, c.ShouldResemble, []byte("re: ping"))
+		c.So(rtt.ReceiveID, c.ShouldResemble, []byte("ww6a112864f8022910"))
+	})
+}
+
+type recordingEnvelopeHandler struct {
+	credentialIDs []string
+}
+
+func (h *recordingEnvelopeHandler) OnIncomingEnvelope(_ context.Context, rx envelope.Envelope) ([]byte, error) {
+	h.credentialIDs = append(h.credentialIDs, rx.CredentialID)
+	return nil, nil
+}
+
+func TestLowlevelHandlerCredentialRotation(t *testing.T) {
+	c.Convey("凭据轮换", t, func() {
+		//nolint: gosec  // randomly generated for test purposes only
+		oldCred := Credential{
+			ID:             "old",
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+		//nolint: gosec  // randomly generated for test purposes only
+		newCred := Credential{
+			ID:             "new",
+			Token:          "QDG6eK3bOvFpBMb2hsSPKBPHXbjoDrqD",
+			EncodingAESKey: "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C",
+		}
+
+		eh := &recordingEnvelopeHandler{}
+		handler, err := NewLowlevelHandlerWithCredentials([]Credential{newCred, oldCred}, eh)
+		c.So(err, c.ShouldBeNil)
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		post := func(cred Credential) int {
+			ep, err := envelope.NewProcessor(cred.Token, cred.EncodingAESKey)
+			c.So(err, c.ShouldBeNil)
+			pkt, err := ep.MakeOutgoingEnvelope([]byte("ping"))
+			c.So(err, c.ShouldBeNil)
+			reqURL, err := makeSignedRequestURL(server.URL+"/", pkt)
+			c.So(err, c.ShouldBeNil)
+
+			resp, err := http.DefaultClient.Post(reqURL, "application/xml", bytes.NewReader(pkt))
+			c.So(err, c.ShouldBeNil)
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		c.Convey("新旧凭据都能通过，并报告匹配到的凭据", func() {
+			c.So(post(newCred), c.ShouldEqual, http.StatusOK)
+			c.So(post(oldCred), c.ShouldEqual, http.StatusOK)
+			c.So(eh.credentialIDs, c.ShouldResemble, []string{"new", "old"})
+		})
+
+		c.Convey("停用旧凭据后立即拒绝", func() {
+			c.So(handler.RetireCredential("old"), c.ShouldBeNil)
+			c.So(post(oldCred), c.ShouldEqual, http.StatusBadRequest)
+			c.So(post(newCred), c.ShouldEqual, http.StatusOK)
+			c.So(eh.credentialIDs, c.ShouldResemble, []string{"new"})
+		})
+
+		c.Convey("不能停用未知或仅剩的凭据", func() {
+			c.So(handler.RetireCredential("nope"), c.ShouldNotBeNil)
+			c.So(handler.RetireCredential("old"), c.ShouldBeNil)
+			c.So(handler.RetireCredential("new"), c.ShouldNotBeNil)
+		})
+
+		c.Convey("整体替换凭据", func() {
+			c.So(handler.SetCredentials(nil), c.ShouldNotBeNil)
+			c.So(handler.SetCredentials([]Credential{oldCred}), c.ShouldBeNil)
+			c.So(post(newCred), c.ShouldEqual, http.StatusBadRequest)
+			c.So(post(oldCred), c.ShouldEqual, http.StatusOK)
+		})
+	})
+}
@@ -1,5 +1,11 @@
 package pkcs7
 
+import (
+	"errors"
+)
+
+var errMalformedPadding = errors.New("malformed PKCS#7 padding")
+
 func Pad(x []byte) []byte {
 	numPadBytes := 32 - len(x)%32
 	padByte := byte(numPadBytes)
@@ -11,8 +17,22 @@ func Pad(x []byte) []byte {
 	return tmp
 }
 
-func Unpad(x []byte) []byte {
+func Unpad(x []byte) ([]byte, error) {
+	if len(x) == 0 {
+		return nil, errMalformedPadding
+	}
+
 	// last byte is number of suffix bytes to remove
 	n := int(x[len(x)-1])
-	return x[:len(x)-n]
+	if n == 0 || n > 32 || n > len(x) {
+		return nil, errMalformedPadding
+	}
+
+	for _, b := range x[len(x)-n:] {
+		if int(b) != n {
+			return nil, errMalformedPadding
+		}
+	}
+
+	return x[:len(x)-n], nil
 }
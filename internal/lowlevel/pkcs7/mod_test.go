@@ -68,3 +68,27 @@ func TestPKCS7Pad(t *testing.T) {
 		})
 	})
 }
+
+func TestPKCS7Unpad(t *testing.T) {
+	c.Convey("PKCS#7 Unpadding", t, func() {
+		c.Convey("round trip", func() {
+			x := []byte("foobar")
+			y, err := Unpad(Pad(x))
+			c.So(err, c.ShouldBeNil)
+			c.So(y, c.ShouldResemble, x)
+		})
+
+		c.Convey("malformed padding should be rejected", func() {
+			for _, x := range [][]byte{
+				{},
+				{65, 0},
+				{65, 33},
+				{65, 3, 3},
+				{65, 2, 1, 2},
+			} {
+				_, err := Unpad(x)
+				c.So(err, c.ShouldNotBeNil)
+			}
+		})
+	})
+}
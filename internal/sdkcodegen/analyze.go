@@ -477,6 +477,8 @@ func parseAPIMethod(x string) (apiMethod, error) {
 		return apiMethodGET, nil
 	case "POST":
 		return apiMethodPOSTJSON, nil
+	case "POST(ctx)":
+		return apiMethodPOSTJSONWithContext, nil
 	case "POST(media)":
 		return apiMethodPOSTMedia, nil
 	default:
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/format"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -33,6 +34,11 @@ func (e *goEmitter) EmitCode(spec *hir) error {
 	e.e("package workwx\n")
 	e.e("\n")
 
+	if spec.needsContext() {
+		e.e("import \"context\"\n")
+		e.e("\n")
+	}
+
 	for i := range spec.topics {
 		err := e.emitTopic(&spec.topics[i])
 		if err != nil {
@@ -117,15 +123,22 @@ func (e *goEmitter) emitModelField(x *apiModelField) error {
 	if len(x.tags) > 0 {
 		e.e("`")
 
+		// emit tags in a stable order, so regenerating doesn't shuffle them
+		keys := make([]string, 0, len(x.tags))
+		for k := range x.tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
 		isFirst := true
-		for k, v := range x.tags {
+		for _, k := range keys {
 			if isFirst {
 				isFirst = false
 			} else {
 				e.e(" ")
 			}
 
-			e.e("%s:\"%s\"", k, v)
+			e.e("%s:\"%s\"", k, x.tags[k])
 		}
 
 		e.e("`")
@@ -164,6 +177,8 @@ func (e *goEmitter) emitAPICall(x *apiCall) error {
 		execFnName = "executeQyapiGet"
 	case apiMethodPOSTJSON:
 		execFnName = "executeQyapiJSONPost"
+	case apiMethodPOSTJSONWithContext:
+		execFnName = "executeQyapiJSONPostWithContext"
 	case apiMethodPOSTMedia:
 		execFnName = "executeQyapiMediaUpload"
 	default:
@@ -172,9 +187,15 @@ func (e *goEmitter) emitAPICall(x *apiCall) error {
 
 	// TODO: override the receiver of method
 	e.emitDoc(ident, x.doc)
-	e.e("func (c *WorkwxApp) %s(req %s) (%s, error) {\n", ident, x.reqType, x.respType)
-	e.e("var resp %s\n", x.respType)
-	e.e("err := %s(c, \"%s\", req, &resp, %v)\n", execFnName, x.httpURI, x.needsAccessToken)
+	if x.method == apiMethodPOSTJSONWithContext {
+		e.e("func (c *WorkwxApp) %s(ctx context.Context, req %s) (%s, error) {\n", ident, x.reqType, x.respType)
+		e.e("var resp %s\n", x.respType)
+		e.e("err := %s(ctx, c, \"%s\", req, &resp, %v)\n", execFnName, x.httpURI, x.needsAccessToken)
+	} else {
+		e.e("func (c *WorkwxApp) %s(req %s) (%s, error) {\n", ident, x.reqType, x.respType)
+		e.e("var resp %s\n", x.respType)
+		e.e("err := %s(c, \"%s\", req, &resp, %v)\n", execFnName, x.httpURI, x.needsAccessToken)
+	}
 	e.e("if err != nil {\n")
 	// TODO: error_chain
 	e.e("return %s{}, err\n", x.respType)
@@ -46,6 +46,7 @@ const (
 	apiMethodUnknown apiMethod = iota
 	apiMethodGET
 	apiMethodPOSTJSON
+	apiMethodPOSTJSONWithContext
 	apiMethodPOSTMedia
 )
 
@@ -63,3 +64,15 @@ type apiCall struct {
 	method  apiMethod
 	httpURI string
 }
+
+// Whether any API call takes a context, so the generated code must import it.
+func (x *hir) needsContext() bool {
+	for i := range x.topics {
+		for j := range x.topics[i].calls {
+			if x.topics[i].calls[j].method == apiMethodPOSTJSONWithContext {
+				return true
+			}
+		}
+	}
+	return false
+}
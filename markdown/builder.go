@@ -0,0 +1,251 @@
+// Package markdown 构造企业微信 markdown 消息内容。
+//
+// 企业微信仅支持 markdown 语法的子集，另有 `<font color="...">` 颜色标签与
+// 群机器人专用的 `<@userid>` 提醒语法；内容经群机器人发送时不超过 4096 个字节，
+// 作为应用消息或群聊会话消息发送时不超过 2048 个字节。
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/EnxZhou/go-workwx"
+)
+
+const (
+	// MaxContentBytes 应用消息、群聊会话消息 markdown 内容的最大字节数
+	MaxContentBytes = 2048
+	// MaxWebhookContentBytes 群机器人 markdown 消息内容的最大字节数
+	MaxWebhookContentBytes = 4096
+)
+
+// ErrContentTooLong 内容超出所经渠道的字节数上限
+var ErrContentTooLong = errors.New("markdown: content too long")
+
+// ErrMentionNotSupported 仅群机器人消息支持 `<@userid>` 提醒
+var ErrMentionNotSupported = errors.New("markdown: mentions are only supported by webhook messages")
+
+// ErrInvalidElement 元素参数不合法
+var ErrInvalidElement = errors.New("markdown: invalid element")
+
+// Color 字体颜色
+type Color string
+
+const (
+	// ColorInfo 绿色
+	ColorInfo Color = "info"
+	// ColorComment 灰色
+	ColorComment Color = "comment"
+	// ColorWarning 橙红色
+	ColorWarning Color = "warning"
+)
+
+// userIDRe 成员 UserID 的合法字符
+var userIDRe = regexp.MustCompile(`^[0-9A-Za-z_\-.@]{1,64}$`)
+
+// Builder markdown 消息内容构造器
+//
+// 各方法写入的文本均会转义，以原样显示；块级元素（标题、引用）独占一行。
+// 参数不合法时记录首个错误，由 Build 返回，其后的写入被忽略。
+type Builder struct {
+	buf      strings.Builder
+	err      error
+	mentions bool
+}
+
+// New 构造一个空的 Builder
+func New() *Builder {
+	return &Builder{}
+}
+
+// Text 写入普通文本，可以包含换行
+func (b *Builder) Text(s string) *Builder {
+	return b.write(escape(s))
+}
+
+// Newline 写入换行
+func (b *Builder) Newline() *Builder {
+	return b.write("\n")
+}
+
+// Heading 写入 level 级标题，level 取值 1 到 6
+func (b *Builder) Heading(level int, s string) *Builder {
+	if level < 1 || level > 6 {
+		return b.fail(fmt.Errorf("%w: heading level %d", ErrInvalidElement, level))
+	}
+	return b.block(strings.Repeat("#", level) + " " + escapeInline(s))
+}
+
+// Bold 写入加粗文本
+func (b *Builder) Bold(s string) *Builder {
+	return b.write("**" + escapeInline(s) + "**")
+}
+
+// Link 写入链接
+func (b *Builder) Link(text string, url string) *Builder {
+	if url == "" {
+		return b.fail(fmt.Errorf("%w: empty link url", ErrInvalidElement))
+	}
+	return b.write("[" + escapeInline(text) + "](" + escapeURL(url) + ")")
+}
+
+// Quote 写入引用，多行文本的每一行均被引用
+func (b *Builder) Quote(s string) *Builder {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = "> " + escapeInline(l)
+	}
+	return b.block(strings.Join(lines, "\n"))
+}
+
+// Colored 写入带颜色的文本
+func (b *Builder) Colored(color Color, s string) *Builder {
+	switch color {
+	case ColorInfo, ColorComment, ColorWarning:
+	default:
+		return b.fail(fmt.Errorf("%w: font color %q", ErrInvalidElement, color))
+	}
+	return b.write(`<font color="` + string(color) + `">` + escapeInline(s) + "</font>")
+}
+
+// Mention 写入对成员的提醒，仅群机器人消息支持
+func (b *Builder) Mention(userID string) *Builder {
+	if !userIDRe.MatchString(userID) {
+		return b.fail(fmt.Errorf("%w: mention user id %q", ErrInvalidElement, userID))
+	}
+	b.mentions = true
+	return b.write("<@" + userID + ">")
+}
+
+// Code 写入行内代码，企业微信不支持跨行代码
+func (b *Builder) Code(s string) *Builder {
+	if strings.Contains(s, "\n") {
+		return b.fail(fmt.Errorf("%w: multi-line code", ErrInvalidElement))
+	}
+
+	// 以比内容中最长的连续反引号更长的反引号包围，内容无需转义
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return b.write(fence + s + fence)
+}
+
+// String 返回已写入的内容，不做校验
+func (b *Builder) String() string {
+	return b.buf.String()
+}
+
+// Len 返回已写入内容的字节数
+func (b *Builder) Len() int {
+	return b.buf.Len()
+}
+
+// Build 校验并返回经由 ch 发送的消息内容
+//
+// ch 为 workwx.SendChannelWebhook 时可以包含提醒，内容不超过 MaxWebhookContentBytes；
+// 否则内容不超过 MaxContentBytes。超出时返回 ErrContentTooLong。
+func (b *Builder) Build(ch workwx.SendChannel) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	limit := MaxContentBytes
+	switch ch {
+	case workwx.SendChannelApp, workwx.SendChannelAppchat:
+		if b.mentions {
+			return "", ErrMentionNotSupported
+		}
+	case workwx.SendChannelWebhook:
+		limit = MaxWebhookContentBytes
+	default:
+		return "", fmt.Errorf("%w: markdown message via %s", workwx.ErrMessageNotSupported, ch)
+	}
+
+	if b.buf.Len() > limit {
+		return "", fmt.Errorf("%w: %d bytes via %s, at most %d", ErrContentTooLong, b.buf.Len(), ch, limit)
+	}
+
+	return b.buf.String(), nil
+}
+
+// Message 校验并返回经由 ch 发送的 markdown 消息，可直接用于 Send
+func (b *Builder) Message(ch workwx.SendChannel) (workwx.MarkdownMessage, error) {
+	content, err := b.Build(ch)
+	if err != nil {
+		return workwx.MarkdownMessage{}, err
+	}
+	return workwx.MarkdownMessage{Content: content}, nil
+}
+
+func (b *Builder) write(s string) *Builder {
+	if b.err == nil {
+		b.buf.WriteString(s)
+	}
+	return b
+}
+
+// block 写入独占一行的块级元素
+func (b *Builder) block(s string) *Builder {
+	if b.buf.Len() > 0 && !strings.HasSuffix(b.buf.String(), "\n") {
+		b.write("\n")
+	}
+	return b.write(s + "\n")
+}
+
+func (b *Builder) fail(err error) *Builder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// markdownEscaper 转义 markdown 与颜色、提醒标签的特殊字符
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`#`, `\#`,
+	`>`, `\>`,
+	`<`, `\<`,
+	`~`, `\~`,
+)
+
+func escape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeInline 转义行内元素的文本，行内元素不能跨行
+func escapeInline(s string) string {
+	return escape(strings.ReplaceAll(s, "\n", " "))
+}
+
+// urlEscaper 转义链接地址中会提前结束链接的字符
+var urlEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+	"\n", "",
+)
+
+func escapeURL(s string) string {
+	return urlEscaper.Replace(s)
+}
+
+func longestRun(s string, r byte) int {
+	longest, cur := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == r {
+			cur++
+			longest = max(longest, cur)
+		} else {
+			cur = 0
+		}
+	}
+	return longest
+}
@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/EnxZhou/go-workwx"
+)
+
+func TestBuilder(t *testing.T) {
+	c.Convey("构造 markdown 消息内容", t, func() {
+		c.Convey("各类元素", func() {
+			b := New().
+				Heading(2, "告警 #1").
+				Text("服务 ").Bold("api_*").Text(" 出现 ").Colored(ColorWarning, "<5xx>").Text(" 错误").
+				Quote("首次出现\n持续 3 分钟").
+				Link("查看 [详情]", "https://example.com/a b(1)").Newline().
+				Code("a `b` c")
+
+			content, err := b.Build(workwx.SendChannelApp)
+			c.So(err, c.ShouldBeNil)
+			c.So(content, c.ShouldEqual, strings.Join([]string{
+				`## 告警 \#1`,
+				"服务 **api\\_\\*** 出现 <font color=\"warning\">\\<5xx\\></font> 错误",
+				"> 首次出现",
+				"> 持续 3 分钟",
+				`[查看 \[详情\]](https://example.com/a%20b%281%29)`,
+				"``a `b` c``",
+			}, "\n"))
+		})
+
+		c.Convey("仅群机器人支持提醒", func() {
+			b := New().Text("请处理 ").Mention("zhangsan")
+
+			content, err := b.Build(workwx.SendChannelWebhook)
+			c.So(err, c.ShouldBeNil)
+			c.So(content, c.ShouldEqual, "请处理 <@zhangsan>")
+
+			_, err = b.Build(workwx.SendChannelApp)
+			c.So(err, c.ShouldEqual, ErrMentionNotSupported)
+
+			_, err = b.Build(workwx.SendChannelKf)
+			c.So(errors.Is(err, workwx.ErrMessageNotSupported), c.ShouldBeTrue)
+		})
+
+		c.Convey("记录首个不合法的元素", func() {
+			b := New().Heading(7, "x").Mention("<script>").Text("y")
+			c.So(b.String(), c.ShouldEqual, "")
+
+			_, err := b.Build(workwx.SendChannelWebhook)
+			c.So(errors.Is(err, ErrInvalidElement), c.ShouldBeTrue)
+			c.So(err.Error(), c.ShouldContainSubstring, "heading level 7")
+		})
+
+		c.Convey("字节数上限", func() {
+			b := New().Text(strings.Repeat("字", MaxContentBytes/3))
+			_, err := b.Build(workwx.SendChannelApp)
+			c.So(err, c.ShouldBeNil)
+
+			msg, err := b.Text("多").Message(workwx.SendChannelApp)
+			c.So(errors.Is(err, ErrContentTooLong), c.ShouldBeTrue)
+			c.So(msg.Content, c.ShouldEqual, "")
+
+			b = New().Text(strings.Repeat("a", MaxContentBytes))
+			_, err = b.Build(workwx.SendChannelAppchat)
+			c.So(err, c.ShouldBeNil)
+			_, err = b.Text("a").Build(workwx.SendChannelAppchat)
+			c.So(errors.Is(err, ErrContentTooLong), c.ShouldBeTrue)
+
+			// 群机器人的上限更高
+			_, err = b.Build(workwx.SendChannelWebhook)
+			c.So(err, c.ShouldBeNil)
+			_, err = b.Text(strings.Repeat("a", MaxWebhookContentBytes-MaxContentBytes)).Build(workwx.SendChannelWebhook)
+			c.So(errors.Is(err, ErrContentTooLong), c.ShouldBeTrue)
+		})
+	})
+}
@@ -1,7 +1,10 @@
 package workwx
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 )
 
 // SendTextMessage 发送文本消息
@@ -13,7 +16,19 @@ func (c *WorkwxApp) SendTextMessage(
 	content string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(recipient, "text", map[string]any{"content": content}, isSafe)
+	_, err := c.SendTextMessageWithResult(recipient, content, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendTextMessageWithResult 发送文本消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendTextMessageWithResult(
+	recipient *Recipient,
+	content string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{TextMessage{Content: content}}, opts)
 }
 
 // SendImageMessage 发送图片消息
@@ -25,13 +40,19 @@ func (c *WorkwxApp) SendImageMessage(
 	mediaID string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"image",
-		map[string]any{
-			"media_id": mediaID,
-		}, isSafe,
-	)
+	_, err := c.SendImageMessageWithResult(recipient, mediaID, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendImageMessageWithResult 发送图片消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendImageMessageWithResult(
+	recipient *Recipient,
+	mediaID string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{ImageMessage{MediaID: mediaID}}, opts)
 }
 
 // SendVoiceMessage 发送语音消息
@@ -43,13 +64,19 @@ func (c *WorkwxApp) SendVoiceMessage(
 	mediaID string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"voice",
-		map[string]any{
-			"media_id": mediaID,
-		}, isSafe,
-	)
+	_, err := c.SendVoiceMessageWithResult(recipient, mediaID, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendVoiceMessageWithResult 发送语音消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendVoiceMessageWithResult(
+	recipient *Recipient,
+	mediaID string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{VoiceMessage{MediaID: mediaID}}, opts)
 }
 
 // SendVideoMessage 发送视频消息
@@ -63,15 +90,26 @@ func (c *WorkwxApp) SendVideoMessage(
 	title string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"video",
-		map[string]any{
-			"media_id":    mediaID,
-			"description": description, // TODO: 零值
-			"title":       title,       // TODO: 零值
-		}, isSafe,
-	)
+	_, err := c.SendVideoMessageWithResult(recipient, mediaID, description, title, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendVideoMessageWithResult 发送视频消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendVideoMessageWithResult(
+	recipient *Recipient,
+	mediaID string,
+	description string,
+	title string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	msg := VideoMessage{
+		MediaID:     mediaID,
+		Title:       title,
+		Description: description,
+	}
+	return c.Send(context.Background(), recipient, uncheckedMessage{msg}, opts)
 }
 
 // SendFileMessage 发送文件消息
@@ -83,13 +121,19 @@ func (c *WorkwxApp) SendFileMessage(
 	mediaID string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"file",
-		map[string]any{
-			"media_id": mediaID,
-		}, isSafe,
-	)
+	_, err := c.SendFileMessageWithResult(recipient, mediaID, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendFileMessageWithResult 发送文件消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendFileMessageWithResult(
+	recipient *Recipient,
+	mediaID string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{FileMessage{MediaID: mediaID}}, opts)
 }
 
 // SendTextCardMessage 发送文本卡片消息
@@ -104,16 +148,28 @@ func (c *WorkwxApp) SendTextCardMessage(
 	buttonText string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"textcard",
-		map[string]any{
-			"title":       title,
-			"description": description,
-			"url":         url,
-			"btntxt":      buttonText, // TODO: 零值
-		}, isSafe,
-	)
+	_, err := c.SendTextCardMessageWithResult(recipient, title, description, url, buttonText, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendTextCardMessageWithResult 发送文本卡片消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendTextCardMessageWithResult(
+	recipient *Recipient,
+	title string,
+	description string,
+	url string,
+	buttonText string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	msg := TextCardMessage{
+		Title:       title,
+		Description: description,
+		URL:         url,
+		ButtonText:  buttonText,
+	}
+	return c.Send(context.Background(), recipient, uncheckedMessage{msg}, opts)
 }
 
 // SendNewsMessage 发送图文消息
@@ -125,13 +181,19 @@ func (c *WorkwxApp) SendNewsMessage(
 	articles []Article,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"news",
-		map[string]any{
-			"articles": articles,
-		}, isSafe,
-	)
+	_, err := c.SendNewsMessageWithResult(recipient, articles, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendNewsMessageWithResult 发送图文消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendNewsMessageWithResult(
+	recipient *Recipient,
+	articles []Article,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{NewsMessage{Articles: articles}}, opts)
 }
 
 // SendMPNewsMessage 发送 mpnews 类型的图文消息
@@ -143,13 +205,19 @@ func (c *WorkwxApp) SendMPNewsMessage(
 	mparticles []MPArticle,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"mpnews",
-		map[string]any{
-			"articles": mparticles,
-		}, isSafe,
-	)
+	_, err := c.SendMPNewsMessageWithResult(recipient, mparticles, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendMPNewsMessageWithResult 发送 mpnews 类型的图文消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendMPNewsMessageWithResult(
+	recipient *Recipient,
+	mparticles []MPArticle,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{MPNewsMessage{Articles: mparticles}}, opts)
 }
 
 // SendMarkdownMessage 发送 Markdown 消息
@@ -163,7 +231,19 @@ func (c *WorkwxApp) SendMarkdownMessage(
 	content string,
 	isSafe bool,
 ) error {
-	return c.sendMessage(recipient, "markdown", map[string]any{"content": content}, isSafe)
+	_, err := c.SendMarkdownMessageWithResult(recipient, content, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendMarkdownMessageWithResult 发送 Markdown 消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendMarkdownMessageWithResult(
+	recipient *Recipient,
+	content string,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{MarkdownMessage{Content: content}}, opts)
 }
 
 // SendTaskCardMessage 发送 任务卡片 消息
@@ -176,17 +256,30 @@ func (c *WorkwxApp) SendTaskCardMessage(
 	btn []TaskCardBtn,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"taskcard",
-		map[string]any{
-			"title":       title,
-			"description": description,
-			"url":         url,
-			"task_id":     taskid,
-			"btn":         btn,
-		}, isSafe,
-	)
+	_, err := c.SendTaskCardMessageWithResult(recipient, title, description, url, taskid, btn, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
+}
+
+// SendTaskCardMessageWithResult 发送 任务卡片 消息，并返回发送结果
+//
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendTaskCardMessageWithResult(
+	recipient *Recipient,
+	title string,
+	description string,
+	url string,
+	taskid string,
+	btn []TaskCardBtn,
+	opts *SendOptions,
+) (*SendResult, error) {
+	msg := TaskCardMessage{
+		Title:       title,
+		Description: description,
+		URL:         url,
+		TaskID:      taskid,
+		Buttons:     btn,
+	}
+	return c.Send(context.Background(), recipient, uncheckedMessage{msg}, opts)
 }
 
 // SendTemplateCardMessage 发送卡片模板消息
@@ -195,41 +288,222 @@ func (c *WorkwxApp) SendTemplateCardMessage(
 	templateCard TemplateCard,
 	isSafe bool,
 ) error {
-	return c.sendMessage(
-		recipient,
-		"template_card",
-		map[string]any{
-			"template_card": templateCard,
-		}, isSafe,
-	)
+	_, err := c.SendTemplateCardMessageWithResult(recipient, templateCard, &SendOptions{Safe: isSafe})
+	return ignorePartialFailure(err)
 }
 
-// sendMessage 发送消息底层接口
+// SendTemplateCardMessageWithResult 发送卡片模板消息，并返回发送结果
 //
-// 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
-// 收件人参数如果仅设置了 `OpenKfID` 字段，则为【客服发送消息】接口调用；
-// 收件人参数如果仅设置了 `Code` 字段，则为【发送欢迎语等事件响应消息】接口调用；
-// 否则为单纯的【发送应用消息】接口调用。
-func (c *WorkwxApp) sendMessage(
+// opts 可以为 nil；部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+func (c *WorkwxApp) SendTemplateCardMessageWithResult(
 	recipient *Recipient,
-	msgtype string,
-	content map[string]any,
-	isSafe bool,
+	templateCard TemplateCard,
+	opts *SendOptions,
+) (*SendResult, error) {
+	return c.Send(context.Background(), recipient, uncheckedMessage{TemplateCardMessage{TemplateCard: templateCard}}, opts)
+}
+
+// UpdateTemplateCard 更新模版卡片消息
+//
+// 可以更新整张卡片，或仅将按钮更新为不可点击状态，详见 TemplateCardUpdateMessage。
+// ResponseCode 72 小时内有效，且只能使用一次。
+//
+// 部分成员不合法时，返回 *SendPartialFailureError。
+func (c *WorkwxApp) UpdateTemplateCard(msg *TemplateCardUpdateMessage) error {
+	req := reqMessageUpdateTemplateCard{
+		UserIDs:      msg.UserIDs,
+		PartyIDs:     msg.PartyIDs,
+		TagIDs:       msg.TagIDs,
+		AtAll:        msg.AtAll != 0,
+		AgentID:      c.AgentID,
+		ResponseCode: msg.ResponseCode,
+		ReplaceName:  msg.Button.ReplaceName,
+	}
+	if msg.TemplateCard.CardType != "" {
+		req.TemplateCard = &msg.TemplateCard
+		req.ReplaceText = msg.ReplaceText
+	}
+
+	return c.updateTemplateCard(req)
+}
+
+// UpdateTemplateCardButton 将指定成员收到的模板卡片的按钮更新为不可点击状态
+//
+// responseCode 为模板卡片事件中的 ResponseCode，72 小时内有效，且只能使用一次；
+// 更新后按钮显示为 replaceName。
+//
+// 部分成员不合法时，返回 *SendPartialFailureError。
+func (c *WorkwxApp) UpdateTemplateCardButton(
+	userIDs []string,
+	responseCode string,
+	replaceName string,
 ) error {
-	sendRequestFunc := c.execMessageSend
-	if !recipient.isValidForMessageSend() {
-		if recipient.isValidForAppchatSend() {
-			sendRequestFunc = c.execAppchatSend
-		} else if recipient.isValidForKfSend() {
-			sendRequestFunc = c.execKfSend
-		} else if recipient.isValidForKfOnEventSend() {
-			sendRequestFunc = c.execKfOnEventSend
-		} else {
-			// TODO: better error
-			return errors.New("recipient invalid for message sending")
+	req := reqMessageUpdateTemplateCard{
+		UserIDs:      userIDs,
+		AgentID:      c.AgentID,
+		ResponseCode: responseCode,
+		ReplaceName:  replaceName,
+	}
+
+	return c.updateTemplateCard(req)
+}
+
+// updateTemplateCard 调用更新模版卡片消息接口，部分成员不合法时返回 *SendPartialFailureError
+func (c *WorkwxApp) updateTemplateCard(req reqMessageUpdateTemplateCard) error {
+	resp, err := c.execMessageUpdateTemplateCard(req)
+	if err != nil {
+		return err
+	}
+
+	if len(resp.InvalidUsers) > 0 {
+		return &SendPartialFailureError{Result: &SendResult{InvalidUsers: resp.InvalidUsers}}
+	}
+	return nil
+}
+
+// RecallMessage 撤回应用消息
+//
+// msgID 为发送应用消息时返回的 SendResult.MsgID，仅可撤回 24 小时内发送的消息。
+func (c *WorkwxApp) RecallMessage(msgID string) error {
+	_, err := c.execMessageRecall(reqMessageRecall{MsgID: msgID})
+	return err
+}
+
+var errNotTemplateCardEvent = errors.New("message is not a template card event")
+
+// UpdateClickedTemplateCardButton 收到模板卡片事件后，将点击者收到的卡片按钮更新为不可点击状态
+//
+// msg 须为模板卡片事件或模板卡片右上角菜单事件，更新后按钮显示为 replaceName。
+func (c *WorkwxApp) UpdateClickedTemplateCardButton(
+	msg *RxMessage,
+	replaceName string,
+) error {
+	var responseCode string
+	if x, ok := msg.EventTemplateCardEvent(); ok {
+		responseCode = x.GetResponseCode()
+	} else if x, ok := msg.EventTemplateCardMenuEvent(); ok {
+		responseCode = x.GetResponseCode()
+	} else {
+		return errNotTemplateCardEvent
+	}
+
+	return c.UpdateTemplateCardButton([]string{msg.FromUserID}, responseCode, replaceName)
+}
+
+// SendOptions 发送消息的可选参数
+type SendOptions struct {
+	// Safe 是否作为保密消息发送，仅【发送应用消息】与【发送消息到群聊会话】支持
+	Safe bool
+	// EnableIDTrans 是否开启 id 转译，仅【发送应用消息】支持
+	EnableIDTrans bool
+	// EnableDuplicateCheck 是否开启重复消息检查，仅【发送应用消息】支持
+	//
+	// 开启后，DuplicateCheckInterval 时间内内容相同的消息不会重复发送，
+	// 可避免重试导致收件人收到多条相同消息。
+	EnableDuplicateCheck bool
+	// DuplicateCheckInterval 重复消息检查的时间间隔，以秒为单位计，最长 4 小时；
+	// 为零时使用服务端默认值 1800 秒。仅在开启 EnableDuplicateCheck 时可以设置
+	DuplicateCheckInterval time.Duration
+	// Chunking 不为 nil 时，【发送应用消息】的收件人超出单次上限则拆分为多批发送
+	//
+	// 为 nil 时收件人超出上限视为不合法。
+	Chunking *ChunkingConfig
+}
+
+// maxDuplicateCheckInterval 重复消息检查的最长时间间隔
+const maxDuplicateCheckInterval = 4 * time.Hour
+
+// ErrInvalidDuplicateCheckInterval 重复消息检查的时间间隔不在 1 秒到 4 小时之间
+var ErrInvalidDuplicateCheckInterval = fmt.Errorf("%w: duplicate check interval must be between 1s and 4h", ErrInvalidMessage)
+
+// validateFor 校验各选项能否用于经由 ch 发送
+func (o *SendOptions) validateFor(ch SendChannel) error {
+	if o.Safe && ch != SendChannelApp && ch != SendChannelAppchat {
+		return fmt.Errorf("%w: safe message via %s", ErrMessageNotSupported, ch)
+	}
+	if o.EnableIDTrans && ch != SendChannelApp {
+		return fmt.Errorf("%w: id translation via %s", ErrMessageNotSupported, ch)
+	}
+	if (o.EnableDuplicateCheck || o.DuplicateCheckInterval != 0) && ch != SendChannelApp {
+		return fmt.Errorf("%w: duplicate check via %s", ErrMessageNotSupported, ch)
+	}
+	if o.DuplicateCheckInterval != 0 {
+		// 未开启重复消息检查时间隔不会发给服务端，与其悄悄丢弃不如报错
+		if !o.EnableDuplicateCheck {
+			return fmt.Errorf("%w: duplicate check interval without duplicate check", ErrInvalidMessage)
+		}
+		if o.DuplicateCheckInterval < time.Second || o.DuplicateCheckInterval > maxDuplicateCheckInterval {
+			return ErrInvalidDuplicateCheckInterval
+		}
+	}
+
+	return nil
+}
+
+// MessageSender 消息发送方，WorkwxApp 与 WebhookClient 均实现了本接口
+type MessageSender interface {
+	// Send 发送消息
+	Send(ctx context.Context, recipient *Recipient, msg Message, opts *SendOptions) (*SendResult, error)
+}
+
+var _ MessageSender = (*WorkwxApp)(nil)
+
+// Send 发送消息
+//
+// 按收件人所设置的字段选择发送渠道：仅设置 `ChatID` 时为【发送消息到群聊会话】，
+// 仅设置 `OpenKfID` 时为【客服发送消息】，设置 `Code` 时为【发送欢迎语等事件响应消息】，
+// 否则为【发送应用消息】。消息内容在发送前按渠道校验，不合法时返回的错误包装了
+// ErrMessageNotSupported 或 ErrInvalidMessage。opts 可以为 nil。
+//
+// 部分收件人不合法或未获许可时，返回发送结果及 *SendPartialFailureError。
+// 拆分发送时，发送失败的批次以 *SendBatchError 报告，成功批次的结果仍然返回。
+func (c *WorkwxApp) Send(
+	ctx context.Context,
+	recipient *Recipient,
+	msg Message,
+	opts *SendOptions,
+) (*SendResult, error) {
+	if opts == nil {
+		opts = &SendOptions{}
+	}
+
+	if opts.Chunking != nil && recipient.isOversizedForMessageSend() {
+		content, err := messageContent(msg, SendChannelApp)
+		if err != nil {
+			return nil, err
+		}
+		if err := opts.validateFor(SendChannelApp); err != nil {
+			return nil, err
 		}
+		return c.sendChunked(ctx, recipient, msg.MessageType(), content, opts)
 	}
 
+	ch, err := recipient.sendChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := messageContent(msg, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.validateFor(ch); err != nil {
+		return nil, err
+	}
+
+	return c.sendOne(ctx, ch, recipient, msg.MessageType(), content, opts)
+}
+
+// sendOne 经由 ch 发送一次消息，content 须已按 ch 校验
+func (c *WorkwxApp) sendOne(
+	ctx context.Context,
+	ch SendChannel,
+	recipient *Recipient,
+	msgType string,
+	content any,
+	opts *SendOptions,
+) (*SendResult, error) {
 	req := reqMessage{
 		ToUser:   recipient.UserIDs,
 		ToParty:  recipient.PartyIDs,
@@ -238,18 +512,36 @@ func (c *WorkwxApp) sendMessage(
 		AgentID:  c.AgentID,
 		Code:     recipient.Code,
 		OpenKfID: recipient.OpenKfID,
-		MsgType:  msgtype,
+		MsgType:  msgType,
 		Content:  content,
-		IsSafe:   isSafe,
-	}
+		IsSafe:   opts.Safe,
 
-	resp, err := sendRequestFunc(req)
+		EnableIDTrans:          opts.EnableIDTrans,
+		EnableDuplicateCheck:   opts.EnableDuplicateCheck,
+		DuplicateCheckInterval: int64(opts.DuplicateCheckInterval / time.Second),
+	}
 
+	var resp respMessageSend
+	var err error
+	switch ch {
+	case SendChannelApp:
+		resp, err = c.execMessageSend(ctx, req)
+	case SendChannelAppchat:
+		resp, err = c.execAppchatSend(ctx, req)
+	case SendChannelKf:
+		resp, err = c.execKfSend(ctx, req)
+	case SendChannelKfOnEvent:
+		resp, err = c.execKfOnEventSend(ctx, req)
+	default:
+		err = fmt.Errorf("%w: send via %s", ErrMessageNotSupported, ch)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// TODO: what to do with resp?
-	_ = resp
-	return nil
+	result := resp.intoSendResult()
+	if result.HasRejectedRecipients() {
+		return result, &SendPartialFailureError{Result: result}
+	}
+	return result, nil
 }
@@ -0,0 +1,138 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ChunkingConfig 拆分发送的配置
+//
+// 【发送应用消息】单次最多发给 1000 个成员、100 个部门、100 个标签。
+// 开启拆分发送后，超出上限的收件人被拆分为若干批并发发送，结果合并返回。
+//
+// NOTE: 同一成员若经由不同批次（如同时以 UserID 和所在部门指定）被覆盖，可能收到重复消息。
+type ChunkingConfig struct {
+	// Concurrency 同时发送的批次数，默认为 DefaultChunkConcurrency
+	Concurrency int
+	// Interval 相邻两批开始发送的最小间隔，用于控制调用频率，为零则不限制
+	Interval time.Duration
+}
+
+// DefaultChunkConcurrency 拆分发送时默认同时发送的批次数
+const DefaultChunkConcurrency = 4
+
+// SendBatchError 拆分发送时，某一批次发送失败
+//
+// 可以用 Recipient 重新发送该批次。
+type SendBatchError struct {
+	// Index 批次序号，从 0 开始
+	Index int
+	// Recipient 该批次的收件人
+	Recipient Recipient
+	// Err 失败原因
+	Err error
+}
+
+var _ error = (*SendBatchError)(nil)
+
+func (e *SendBatchError) Error() string {
+	return fmt.Sprintf("send batch %d: %v", e.Index, e.Err)
+}
+
+func (e *SendBatchError) Unwrap() error {
+	return e.Err
+}
+
+// sendChunked 拆分收件人并发发送，合并各批次结果
+func (c *WorkwxApp) sendChunked(
+	ctx context.Context,
+	recipient *Recipient,
+	msgType string,
+	content any,
+	opts *SendOptions,
+) (*SendResult, error) {
+	cfg := *opts.Chunking
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultChunkConcurrency
+	}
+
+	batches := recipient.splitForMessageSend()
+	results := make([]*SendResult, len(batches))
+	errs := make([]error, len(batches))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cfg.Concurrency)
+	var lastStart time.Time
+	for i := range batches {
+		if err := waitChunkSlot(ctx, sem, lastStart, cfg.Interval); err != nil {
+			for j := i; j < len(batches); j++ {
+				errs[j] = &SendBatchError{Index: j, Recipient: batches[j], Err: err}
+			}
+			break
+		}
+		lastStart = time.Now()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := c.sendOne(ctx, SendChannelApp, &batches[i], msgType, content, opts)
+			var pe *SendPartialFailureError
+			if err != nil && !errors.As(err, &pe) {
+				errs[i] = &SendBatchError{Index: i, Recipient: batches[i], Err: err}
+				return
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	merged := mergeSendResults(results)
+	if err := errors.Join(errs...); err != nil {
+		return merged, err
+	}
+	if merged.HasRejectedRecipients() {
+		return merged, &SendPartialFailureError{Result: merged}
+	}
+	return merged, nil
+}
+
+// waitChunkSlot 等待可以开始发送下一批
+func waitChunkSlot(ctx context.Context, sem chan struct{}, lastStart time.Time, interval time.Duration) error {
+	if interval > 0 && !lastStart.IsZero() {
+		timer := time.NewTimer(time.Until(lastStart.Add(interval)))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mergeSendResults 合并各批次的发送结果，发送失败的批次对应 nil
+func mergeSendResults(results []*SendResult) *SendResult {
+	merged := &SendResult{Batches: results}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		merged.InvalidUsers = append(merged.InvalidUsers, r.InvalidUsers...)
+		merged.InvalidParties = append(merged.InvalidParties, r.InvalidParties...)
+		merged.InvalidTags = append(merged.InvalidTags, r.InvalidTags...)
+		merged.UnlicensedUsers = append(merged.UnlicensedUsers, r.UnlicensedUsers...)
+	}
+	return merged
+}
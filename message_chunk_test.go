@@ -0,0 +1,88 @@
+package workwx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSendChunked(t *testing.T) {
+	c.Convey("拆分发送", t, func() {
+		var mu sync.Mutex
+		var gotToUsers []string
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var body struct {
+				ToUser string `json:"touser"`
+			}
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &body)
+
+			mu.Lock()
+			gotToUsers = append(gotToUsers, body.ToUser)
+			mu.Unlock()
+
+			users := strings.Split(body.ToUser, "|")
+			switch users[0] {
+			case "u1000":
+				// 第二批中有不合法的成员
+				_, _ = fmt.Fprintf(rw, `{"errcode":0,"errmsg":"ok","invaliduser":"%s","msgid":"m2"}`, users[1])
+			case "u2000":
+				_, _ = rw.Write([]byte(`{"errcode":45033,"errmsg":"api concurrent out of limit"}`))
+			default:
+				_, _ = fmt.Fprintf(rw, `{"errcode":0,"errmsg":"ok","msgid":"m-%s"}`, users[0])
+			}
+		}))
+		defer closeFn()
+
+		users := make([]string, 2500)
+		for i := range users {
+			users[i] = fmt.Sprintf("u%d", i)
+		}
+		to := Recipient{UserIDs: users, PartyIDs: []string{"1"}}
+
+		c.Convey("未开启拆分时收件人超出上限不合法", func() {
+			_, err := app.Send(context.Background(), &to, TextMessage{Content: "x"}, nil)
+			c.So(err, c.ShouldEqual, errInvalidRecipient)
+			c.So(gotToUsers, c.ShouldBeEmpty)
+		})
+
+		c.Convey("合并各批次结果", func() {
+			result, err := app.Send(context.Background(), &to, TextMessage{Content: "x"}, &SendOptions{
+				Chunking: &ChunkingConfig{Concurrency: 2, Interval: time.Millisecond},
+			})
+			c.So(gotToUsers, c.ShouldHaveLength, 3)
+
+			var be *SendBatchError
+			c.So(errors.As(err, &be), c.ShouldBeTrue)
+			c.So(be.Index, c.ShouldEqual, 2)
+			c.So(be.Recipient.UserIDs, c.ShouldResemble, users[2000:])
+			c.So(be.Recipient.PartyIDs, c.ShouldBeNil)
+			var ce *WorkwxClientError
+			c.So(errors.As(err, &ce), c.ShouldBeTrue)
+
+			c.So(result.MsgID, c.ShouldEqual, "")
+			c.So(result.Batches, c.ShouldHaveLength, 3)
+			c.So(result.Batches[0].MsgID, c.ShouldEqual, "m-u0")
+			c.So(result.Batches[1].MsgID, c.ShouldEqual, "m2")
+			c.So(result.Batches[2], c.ShouldBeNil)
+			c.So(result.InvalidUsers, c.ShouldResemble, []string{"u1001"})
+		})
+
+		c.Convey("ctx 结束后不再发送", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := app.Send(ctx, &to, TextMessage{Content: "x"}, &SendOptions{Chunking: &ChunkingConfig{}})
+			c.So(errors.Is(err, context.Canceled), c.ShouldBeTrue)
+		})
+	})
+}
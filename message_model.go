@@ -0,0 +1,511 @@
+package workwx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// SendChannel 消息发送渠道
+type SendChannel int
+
+const (
+	// SendChannelApp 发送应用消息
+	SendChannelApp SendChannel = iota + 1
+	// SendChannelAppchat 发送消息到群聊会话
+	SendChannelAppchat
+	// SendChannelKf 客服发送消息
+	SendChannelKf
+	// SendChannelKfOnEvent 客服发送欢迎语等事件响应消息
+	SendChannelKfOnEvent
+	// SendChannelWebhook 群机器人发送消息
+	SendChannelWebhook
+)
+
+func (ch SendChannel) String() string {
+	switch ch {
+	case SendChannelApp:
+		return "app"
+	case SendChannelAppchat:
+		return "appchat"
+	case SendChannelKf:
+		return "kf"
+	case SendChannelKfOnEvent:
+		return "kf_on_event"
+	case SendChannelWebhook:
+		return "webhook"
+	default:
+		return fmt.Sprintf("SendChannel(%d)", int(ch))
+	}
+}
+
+// ErrMessageNotSupported 消息类型或其部分字段不能经由该渠道发送
+var ErrMessageNotSupported = errors.New("go-workwx: message not supported by channel")
+
+// ErrInvalidMessage 消息内容不合法
+var ErrInvalidMessage = errors.New("go-workwx: invalid message")
+
+// Message 可发送的消息
+//
+// 由本包中的 TextMessage、ImageMessage 等类型实现，经 WorkwxApp.Send 或
+// WebhookClient.Send 发送；发送前会按渠道校验消息内容。
+type Message interface {
+	// MessageType 消息类型，即请求中的 msgtype
+	MessageType() string
+
+	// validate 校验必填字段与长度限制
+	validate(ch SendChannel) error
+
+	// intoContent 校验消息能否经由 ch 发送，并返回请求中 msgtype 对应字段的内容
+	intoContent(ch SendChannel) (any, error)
+}
+
+// messageContent 按渠道校验消息，并返回请求中 msgtype 对应字段的内容
+func messageContent(msg Message, ch SendChannel) (any, error) {
+	content, err := msg.intoContent(ch)
+	if err != nil {
+		return nil, err
+	}
+	if err := msg.validate(ch); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// uncheckedMessage 不校验必填字段与长度限制的消息，供原有的各 Send*Message 方法使用，
+// 以维持其原有语义：由企业微信接口校验消息内容
+type uncheckedMessage struct {
+	Message
+}
+
+func (uncheckedMessage) validate(SendChannel) error { return nil }
+
+func requireChannel(msg Message, ch SendChannel, supported ...SendChannel) error {
+	if slices.Contains(supported, ch) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s message via %s", ErrMessageNotSupported, msg.MessageType(), ch)
+}
+
+func requireField(msg Message, name string, ok bool) error {
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %s message requires %s", ErrInvalidMessage, msg.MessageType(), name)
+}
+
+func requireMaxBytes(msg Message, name string, s string, limit int) error {
+	if len(s) <= limit {
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: %s of %s message exceeds %d bytes",
+		ErrInvalidMessage,
+		name,
+		msg.MessageType(),
+		limit,
+	)
+}
+
+// TextMessage 文本消息
+type TextMessage struct {
+	// Content 消息内容，最长不超过 2048 个字节
+	Content string
+	// Mentions 提醒群成员，仅群机器人消息支持
+	Mentions *Mentions
+}
+
+var _ Message = TextMessage{}
+
+// MessageType 消息类型
+func (TextMessage) MessageType() string { return "text" }
+
+func (m TextMessage) validate(SendChannel) error {
+	return errors.Join(
+		requireField(m, "Content", m.Content != ""),
+		requireMaxBytes(m, "Content", m.Content, 2048),
+	)
+}
+
+func (m TextMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelKf, SendChannelKfOnEvent, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{
+		"content": m.Content,
+	}
+	if m.Mentions != nil {
+		if ch != SendChannelWebhook {
+			return nil, fmt.Errorf("%w: mentions via %s", ErrMessageNotSupported, ch)
+		}
+		if len(m.Mentions.UserIDs) > 0 {
+			obj["mentioned_list"] = m.Mentions.UserIDs
+		}
+		if len(m.Mentions.Mobiles) > 0 {
+			obj["mentioned_mobile_list"] = m.Mentions.Mobiles
+		}
+	}
+
+	return obj, nil
+}
+
+// ImageMessage 图片消息
+type ImageMessage struct {
+	// MediaID 图片媒体文件 id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = ImageMessage{}
+
+// MessageType 消息类型
+func (ImageMessage) MessageType() string { return "image" }
+
+func (m ImageMessage) validate(SendChannel) error {
+	return requireField(m, "MediaID", m.MediaID != "")
+}
+
+func (m ImageMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelKf); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"media_id": m.MediaID}, nil
+}
+
+// VoiceMessage 语音消息
+type VoiceMessage struct {
+	// MediaID 语音文件 id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = VoiceMessage{}
+
+// MessageType 消息类型
+func (VoiceMessage) MessageType() string { return "voice" }
+
+func (m VoiceMessage) validate(SendChannel) error {
+	return requireField(m, "MediaID", m.MediaID != "")
+}
+
+func (m VoiceMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelKf, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"media_id": m.MediaID}, nil
+}
+
+// VideoMessage 视频消息
+type VideoMessage struct {
+	// MediaID 视频媒体文件 id，可以调用上传临时素材接口获取
+	MediaID string
+	// Title 视频消息的标题，不超过 128 个字节，超过会自动截断
+	Title string
+	// Description 视频消息的描述，不超过 512 个字节，超过会自动截断
+	Description string
+}
+
+var _ Message = VideoMessage{}
+
+// MessageType 消息类型
+func (VideoMessage) MessageType() string { return "video" }
+
+func (m VideoMessage) validate(SendChannel) error {
+	return requireField(m, "MediaID", m.MediaID != "")
+}
+
+func (m VideoMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelKf); err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{"media_id": m.MediaID}
+	if ch != SendChannelKf {
+		obj["title"] = m.Title
+		obj["description"] = m.Description
+	}
+	return obj, nil
+}
+
+// FileMessage 文件消息
+type FileMessage struct {
+	// MediaID 文件 id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = FileMessage{}
+
+// MessageType 消息类型
+func (FileMessage) MessageType() string { return "file" }
+
+func (m FileMessage) validate(SendChannel) error {
+	return requireField(m, "MediaID", m.MediaID != "")
+}
+
+func (m FileMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelKf, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"media_id": m.MediaID}, nil
+}
+
+// TextCardMessage 文本卡片消息
+type TextCardMessage struct {
+	// Title 标题，不超过 128 个字节，超过会自动截断
+	Title string
+	// Description 描述，不超过 512 个字节，超过会自动截断
+	Description string
+	// URL 点击后跳转的链接
+	URL string
+	// ButtonText 按钮文字，默认为“详情”，不超过 4 个文字，超过自动截断
+	ButtonText string
+}
+
+var _ Message = TextCardMessage{}
+
+// MessageType 消息类型
+func (TextCardMessage) MessageType() string { return "textcard" }
+
+func (m TextCardMessage) validate(SendChannel) error {
+	return errors.Join(
+		requireField(m, "Title", m.Title != ""),
+		requireField(m, "Description", m.Description != ""),
+		requireField(m, "URL", m.URL != ""),
+	)
+}
+
+func (m TextCardMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat); err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{
+		"title":       m.Title,
+		"description": m.Description,
+		"url":         m.URL,
+	}
+	if m.ButtonText != "" {
+		obj["btntxt"] = m.ButtonText
+	}
+	return obj, nil
+}
+
+// NewsMessage 图文消息
+type NewsMessage struct {
+	// Articles 图文消息，支持 1 到 8 条图文
+	Articles []Article
+}
+
+var _ Message = NewsMessage{}
+
+// MessageType 消息类型
+func (NewsMessage) MessageType() string { return "news" }
+
+func (m NewsMessage) validate(SendChannel) error {
+	return requireField(m, "1 to 8 Articles", len(m.Articles) >= 1 && len(m.Articles) <= 8)
+}
+
+func (m NewsMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"articles": m.Articles}, nil
+}
+
+// MPNewsMessage mpnews 类型的图文消息
+type MPNewsMessage struct {
+	// Articles 图文消息，支持 1 到 8 条图文
+	Articles []MPArticle
+}
+
+var _ Message = MPNewsMessage{}
+
+// MessageType 消息类型
+func (MPNewsMessage) MessageType() string { return "mpnews" }
+
+func (m MPNewsMessage) validate(SendChannel) error {
+	return requireField(m, "1 to 8 Articles", len(m.Articles) >= 1 && len(m.Articles) <= 8)
+}
+
+func (m MPNewsMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"articles": m.Articles}, nil
+}
+
+// MarkdownMessage Markdown 消息
+//
+// 仅支持 Markdown 的子集；经群机器人发送时，以 `<@userid>` 语法提醒群成员。
+// 可以使用 markdown 包构造内容。
+type MarkdownMessage struct {
+	// Content markdown 内容，经群机器人发送时最长不超过 4096 个字节，否则不超过 2048 个字节
+	Content string
+}
+
+var _ Message = MarkdownMessage{}
+
+// MessageType 消息类型
+func (MarkdownMessage) MessageType() string { return "markdown" }
+
+func (m MarkdownMessage) validate(ch SendChannel) error {
+	limit := 2048
+	if ch == SendChannelWebhook {
+		limit = 4096
+	}
+
+	return errors.Join(
+		requireField(m, "Content", m.Content != ""),
+		requireMaxBytes(m, "Content", m.Content, limit),
+	)
+}
+
+func (m MarkdownMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelAppchat, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"content": m.Content}, nil
+}
+
+// TaskCardMessage 任务卡片消息
+type TaskCardMessage struct {
+	// Title 标题，不超过 128 个字节，超过会自动截断
+	Title string
+	// Description 描述，不超过 512 个字节，超过会自动截断
+	Description string
+	// URL 点击后跳转的链接
+	URL string
+	// TaskID 任务 id，同一个应用发送的任务卡片消息的任务 id 不能重复
+	TaskID string
+	// Buttons 按钮列表，按钮个数为 1~2 个
+	Buttons []TaskCardBtn
+}
+
+var _ Message = TaskCardMessage{}
+
+// MessageType 消息类型
+func (TaskCardMessage) MessageType() string { return "taskcard" }
+
+func (m TaskCardMessage) validate(SendChannel) error {
+	return errors.Join(
+		requireField(m, "Title", m.Title != ""),
+		requireField(m, "Description", m.Description != ""),
+		requireField(m, "TaskID", m.TaskID != ""),
+		requireField(m, "1 to 2 Buttons", len(m.Buttons) >= 1 && len(m.Buttons) <= 2),
+	)
+}
+
+func (m TaskCardMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"title":       m.Title,
+		"description": m.Description,
+		"url":         m.URL,
+		"task_id":     m.TaskID,
+		"btn":         m.Buttons,
+	}, nil
+}
+
+// TemplateCardMessage 模板卡片消息
+//
+// 群机器人仅支持文本通知型、图文展示型模板卡片。
+type TemplateCardMessage struct {
+	TemplateCard TemplateCard
+}
+
+var _ Message = TemplateCardMessage{}
+
+// MessageType 消息类型
+func (TemplateCardMessage) MessageType() string { return "template_card" }
+
+func (m TemplateCardMessage) validate(SendChannel) error {
+	return requireField(m, "TemplateCard.CardType", m.TemplateCard.CardType != "")
+}
+
+func (m TemplateCardMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp, SendChannelWebhook); err != nil {
+		return nil, err
+	}
+
+	if ch == SendChannelWebhook {
+		switch m.TemplateCard.CardType {
+		case CardTypeTextNotice, CardTypeNewsNotice:
+		default:
+			return nil, fmt.Errorf(
+				"%w: %s template card via %s",
+				ErrMessageNotSupported,
+				m.TemplateCard.CardType,
+				ch,
+			)
+		}
+	}
+
+	return m.TemplateCard, nil
+}
+
+// MiniProgramNoticeMessage 小程序通知消息
+type MiniProgramNoticeMessage struct {
+	// AppID 小程序 appid，必须是与当前应用关联的小程序
+	AppID string
+	// Page 点击消息卡片后的小程序页面，仅限本小程序内的页面
+	Page string
+	// Title 消息标题，长度限制 4-12 个汉字
+	Title string
+	// Description 消息描述，长度限制 4-12 个汉字
+	Description string
+	// EmphasisFirstItem 是否放大第一个 ContentItems
+	EmphasisFirstItem bool
+	// ContentItems 消息内容键值对，最多允许 10 个 item
+	ContentItems []MiniProgramNoticeItem
+}
+
+// MiniProgramNoticeItem 小程序通知消息的内容键值对
+type MiniProgramNoticeItem struct {
+	// Key 长度 10 个汉字以内
+	Key string `json:"key"`
+	// Value 长度 30 个汉字以内
+	Value string `json:"value"`
+}
+
+var _ Message = MiniProgramNoticeMessage{}
+
+// MessageType 消息类型
+func (MiniProgramNoticeMessage) MessageType() string { return "miniprogram_notice" }
+
+func (m MiniProgramNoticeMessage) validate(SendChannel) error {
+	return errors.Join(
+		requireField(m, "AppID", m.AppID != ""),
+		requireField(m, "Title", m.Title != ""),
+		requireField(m, "at most 10 ContentItems", len(m.ContentItems) <= 10),
+	)
+}
+
+func (m MiniProgramNoticeMessage) intoContent(ch SendChannel) (any, error) {
+	if err := requireChannel(m, ch, SendChannelApp); err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{
+		"appid":               m.AppID,
+		"title":               m.Title,
+		"emphasis_first_item": m.EmphasisFirstItem,
+	}
+	if m.Page != "" {
+		obj["page"] = m.Page
+	}
+	if m.Description != "" {
+		obj["description"] = m.Description
+	}
+	if len(m.ContentItems) > 0 {
+		obj["content_item"] = m.ContentItems
+	}
+	return obj, nil
+}
@@ -0,0 +1,193 @@
+package workwx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMessageIntoContent(t *testing.T) {
+	c.Convey("按渠道校验消息", t, func() {
+		c.Convey("各渠道支持的消息类型", func() {
+			cases := []struct {
+				msg       Message
+				supported []SendChannel
+			}{
+				{TextMessage{Content: "x"}, []SendChannel{SendChannelApp, SendChannelAppchat, SendChannelKf, SendChannelKfOnEvent, SendChannelWebhook}},
+				{ImageMessage{MediaID: "x"}, []SendChannel{SendChannelApp, SendChannelAppchat, SendChannelKf}},
+				{VoiceMessage{MediaID: "x"}, []SendChannel{SendChannelApp, SendChannelAppchat, SendChannelKf, SendChannelWebhook}},
+				{MarkdownMessage{Content: "x"}, []SendChannel{SendChannelApp, SendChannelAppchat, SendChannelWebhook}},
+				{TaskCardMessage{Title: "x", Description: "x", TaskID: "x", Buttons: []TaskCardBtn{{Key: "k", Name: "n"}}}, []SendChannel{SendChannelApp}},
+				{MiniProgramNoticeMessage{AppID: "x", Title: "x"}, []SendChannel{SendChannelApp}},
+			}
+
+			for _, tc := range cases {
+				for ch := SendChannelApp; ch <= SendChannelWebhook; ch++ {
+					_, err := tc.msg.intoContent(ch)
+					if slices.Contains(tc.supported, ch) {
+						c.So(err, c.ShouldBeNil)
+					} else {
+						c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+					}
+				}
+			}
+		})
+
+		c.Convey("必填字段与长度限制", func() {
+			_, err := messageContent(TextMessage{}, SendChannelApp)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+
+			_, err = messageContent(MarkdownMessage{Content: strings.Repeat("a", 2048)}, SendChannelApp)
+			c.So(err, c.ShouldBeNil)
+			_, err = messageContent(MarkdownMessage{Content: strings.Repeat("a", 2049)}, SendChannelApp)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+			_, err = messageContent(MarkdownMessage{Content: strings.Repeat("a", 2049)}, SendChannelAppchat)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+			_, err = messageContent(MarkdownMessage{Content: strings.Repeat("a", 4096)}, SendChannelWebhook)
+			c.So(err, c.ShouldBeNil)
+			_, err = messageContent(MarkdownMessage{Content: strings.Repeat("a", 4097)}, SendChannelWebhook)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+
+			_, err = messageContent(NewsMessage{Articles: make([]Article, 9)}, SendChannelApp)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+		})
+
+		c.Convey("原有的发送方法不校验必填字段", func() {
+			content, err := messageContent(uncheckedMessage{TextCardMessage{}}, SendChannelApp)
+			c.So(err, c.ShouldBeNil)
+			c.So(content, c.ShouldResemble, map[string]any{"title": "", "description": "", "url": ""})
+
+			// 仍然校验渠道
+			_, err = messageContent(uncheckedMessage{TaskCardMessage{}}, SendChannelAppchat)
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+		})
+
+		c.Convey("仅群机器人支持提醒", func() {
+			msg := TextMessage{Content: "x", Mentions: &Mentions{UserIDs: []string{MentionAll}}}
+
+			content, err := msg.intoContent(SendChannelWebhook)
+			c.So(err, c.ShouldBeNil)
+			c.So(content, c.ShouldResemble, map[string]any{
+				"content":        "x",
+				"mentioned_list": []string{MentionAll},
+			})
+
+			_, err = msg.intoContent(SendChannelApp)
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+		})
+
+		c.Convey("群机器人仅支持部分模板卡片", func() {
+			_, err := TemplateCardMessage{TemplateCard: TemplateCard{CardType: CardTypeTextNotice}}.intoContent(SendChannelWebhook)
+			c.So(err, c.ShouldBeNil)
+
+			_, err = TemplateCardMessage{TemplateCard: TemplateCard{CardType: CardTypeVoteInteraction}}.intoContent(SendChannelWebhook)
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+		})
+	})
+}
+
+func TestWorkwxAppSend(t *testing.T) {
+	c.Convey("按收件人选择发送渠道", t, func() {
+		var gotPath string
+		var gotBody map[string]any
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			body, _ := io.ReadAll(r.Body)
+			gotBody = nil
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","msgid":"m1"}`))
+		}))
+		defer closeFn()
+		ctx := context.Background()
+
+		c.Convey("发送应用消息", func() {
+			result, err := app.Send(ctx, &Recipient{UserIDs: []string{"a", "b"}}, MiniProgramNoticeMessage{
+				AppID:        "wx123",
+				Title:        "会议室预订成功通知",
+				ContentItems: []MiniProgramNoticeItem{{Key: "会议室", Value: "402"}},
+			}, &SendOptions{Safe: true})
+			c.So(err, c.ShouldBeNil)
+			c.So(result.MsgID, c.ShouldEqual, "m1")
+			c.So(gotPath, c.ShouldEqual, "/cgi-bin/message/send")
+			c.So(gotBody["msgtype"], c.ShouldEqual, "miniprogram_notice")
+			c.So(gotBody["touser"], c.ShouldEqual, "a|b")
+			c.So(gotBody["safe"], c.ShouldEqual, 1)
+			c.So(gotBody["miniprogram_notice"], c.ShouldResemble, map[string]any{
+				"appid":               "wx123",
+				"title":               "会议室预订成功通知",
+				"emphasis_first_item": false,
+				"content_item":        []any{map[string]any{"key": "会议室", "value": "402"}},
+			})
+		})
+
+		c.Convey("发送消息到群聊会话", func() {
+			_, err := app.Send(ctx, &Recipient{ChatID: "chat1"}, TemplateCardMessage{}, nil)
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+			c.So(gotPath, c.ShouldEqual, "")
+
+			_, err = app.Send(ctx, &Recipient{ChatID: "chat1"}, MarkdownMessage{Content: "**x**"}, nil)
+			c.So(err, c.ShouldBeNil)
+			c.So(gotPath, c.ShouldEqual, "/cgi-bin/appchat/send")
+			c.So(gotBody["chatid"], c.ShouldEqual, "chat1")
+		})
+
+		c.Convey("客服发送消息", func() {
+			_, err := app.Send(ctx, &Recipient{OpenKfID: "kf1"}, TextMessage{Content: "x"}, &SendOptions{Safe: true})
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+
+			_, err = app.Send(ctx, &Recipient{OpenKfID: "kf1"}, VideoMessage{MediaID: "v1", Title: "t"}, nil)
+			c.So(err, c.ShouldBeNil)
+			c.So(gotPath, c.ShouldEqual, "/cgi-bin/kf/send_msg")
+			c.So(gotBody["video"], c.ShouldResemble, map[string]any{"media_id": "v1"})
+		})
+
+		c.Convey("已取消的 ctx", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			_, err := app.Send(ctx, &Recipient{UserIDs: []string{"a"}}, TextMessage{Content: "x"}, nil)
+			c.So(errors.Is(err, context.Canceled), c.ShouldBeTrue)
+		})
+	})
+}
+
+func TestWebhookClientSend(t *testing.T) {
+	c.Convey("群机器人发送消息", t, func() {
+		var gotQuery string
+		var gotBody map[string]any
+		respBody := `{"errcode":0,"errmsg":"ok"}`
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.RawQuery
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(respBody))
+		}))
+		defer server.Close()
+		wh := NewWebhookClient("testkey", WithQYAPIHost(server.URL))
+
+		var sender MessageSender = wh
+		_, err := sender.Send(context.Background(), nil, NewsMessage{Articles: []Article{{Title: "t", URL: "https://example.com"}}}, nil)
+		c.So(err, c.ShouldBeNil)
+		c.So(gotQuery, c.ShouldEqual, "key=testkey")
+		c.So(gotBody["msgtype"], c.ShouldEqual, "news")
+
+		_, err = wh.Send(context.Background(), &Recipient{UserIDs: []string{"a"}}, TextMessage{Content: "x"}, nil)
+		c.So(err, c.ShouldEqual, errWebhookRecipient)
+
+		_, err = wh.Send(context.Background(), nil, ImageMessage{MediaID: "x"}, nil)
+		c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+
+		respBody = `{"errcode":93000,"errmsg":"invalid webhook url"}`
+		err = wh.SendTextMessage("x", nil)
+		var ce *WorkwxClientError
+		c.So(errors.As(err, &ce), c.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,90 @@
+package workwx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SendResult 消息发送结果
+type SendResult struct {
+	// MsgID 消息 ID，可用于撤回应用消息
+	MsgID string
+	// ResponseCode 仅消息类型为按钮交互型、投票选择型、多项选择型的模板卡片消息返回，
+	// 可用于更新卡片，72 小时内有效，且只能使用一次
+	ResponseCode string
+	// InvalidUsers 不合法的成员 UserID
+	InvalidUsers []string
+	// InvalidParties 不合法的部门 ID
+	InvalidParties []string
+	// InvalidTags 不合法的标签 ID
+	InvalidTags []string
+	// UnlicensedUsers 没有基础接口许可（包含已过期）的成员 UserID
+	UnlicensedUsers []string
+	// Batches 拆分发送时各批次的结果，发送失败的批次对应 nil；未拆分发送时为 nil
+	//
+	// 拆分发送时 MsgID、ResponseCode 为空，须从各批次结果中获取；
+	// 其余字段为各批次结果的合并。
+	Batches []*SendResult
+}
+
+// HasRejectedRecipients 是否有收件人未能收到消息
+func (r *SendResult) HasRejectedRecipients() bool {
+	return len(r.InvalidUsers) > 0 ||
+		len(r.InvalidParties) > 0 ||
+		len(r.InvalidTags) > 0 ||
+		len(r.UnlicensedUsers) > 0
+}
+
+func (x respMessageSend) intoSendResult() *SendResult {
+	return &SendResult{
+		MsgID:           x.MsgID,
+		ResponseCode:    x.ResponseCode,
+		InvalidUsers:    splitRecipientList(x.InvalidUsers),
+		InvalidParties:  splitRecipientList(x.InvalidParties),
+		InvalidTags:     splitRecipientList(x.InvalidTags),
+		UnlicensedUsers: splitRecipientList(x.UnlicensedUsers),
+	}
+}
+
+// splitRecipientList 拆分以 "|" 分隔的收件人列表
+func splitRecipientList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "|")
+}
+
+// SendPartialFailureError 消息已发出，但部分收件人不合法或未获许可
+//
+// 此时 Result 中的 MsgID 等字段仍然有效。
+type SendPartialFailureError struct {
+	Result *SendResult
+}
+
+var _ error = (*SendPartialFailureError)(nil)
+
+func (e *SendPartialFailureError) Error() string {
+	var parts []string
+	add := func(name string, ids []string) {
+		if len(ids) > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, strings.Join(ids, "|")))
+		}
+	}
+	add("invaliduser", e.Result.InvalidUsers)
+	add("invalidparty", e.Result.InvalidParties)
+	add("invalidtag", e.Result.InvalidTags)
+	add("unlicenseduser", e.Result.UnlicensedUsers)
+
+	return "message partially sent: " + strings.Join(parts, " ")
+}
+
+// ignorePartialFailure 供不返回 SendResult 的发送方法使用，维持其原有语义：
+// 只要消息发出即视为成功
+func ignorePartialFailure(err error) error {
+	var e *SendPartialFailureError
+	if errors.As(err, &e) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,285 @@
+package workwx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+type staticTokenProvider string
+
+func (p staticTokenProvider) GetToken(context.Context) (string, error) {
+	return string(p), nil
+}
+
+// newFakeQyapiApp 构造一个请求发往 handler 的 WorkwxApp
+func newFakeQyapiApp(handler http.Handler) (*WorkwxApp, func()) {
+	server := httptest.NewServer(handler)
+	app := New(
+		"testcorpid",
+		WithQYAPIHost(server.URL),
+		WithAccessTokenProvider(staticTokenProvider("testtoken")),
+	).WithApp("testsecret", 1000002)
+
+	return app, server.Close
+}
+
+func TestUpdateClickedTemplateCardButton(t *testing.T) {
+	c.Convey("点击模板卡片后更新按钮", t, func() {
+		var gotPath string
+		var gotBody map[string]any
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","invaliduser":[]}`))
+		}))
+		defer closeFn()
+
+		c.Convey("模板卡片事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[template_card_event]]></Event><EventKey><![CDATA[key111]]></EventKey><TaskId><![CDATA[taskid111]]></TaskId><CardType><![CDATA[vote_interaction]]></CardType><ResponseCode><![CDATA[ResponseCode]]></ResponseCode><AgentID>1</AgentID><SelectedItems><SelectedItem><QuestionKey><![CDATA[QuestionKey1]]></QuestionKey><OptionIds><OptionId><![CDATA[OptionId1]]></OptionId><OptionId><![CDATA[OptionId2]]></OptionId></OptionIds></SelectedItem></SelectedItems></xml>")
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventTemplateCardEvent()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetTaskID(), c.ShouldEqual, "taskid111")
+			c.So(y.GetCardType(), c.ShouldEqual, "vote_interaction")
+			c.So(y.GetSelectedItems(), c.ShouldResemble, []TemplateCardSelectedItem{
+				{QuestionKey: "QuestionKey1", OptionIDs: []string{"OptionId1", "OptionId2"}},
+			})
+
+			_, ok = msg.EventTemplateCardMenuEvent()
+			c.So(ok, c.ShouldBeFalse)
+
+			err = app.UpdateClickedTemplateCardButton(msg, "已同意")
+			c.So(err, c.ShouldBeNil)
+			c.So(gotPath, c.ShouldEqual, "/cgi-bin/message/update_template_card")
+			c.So(gotBody["userids"], c.ShouldResemble, []any{"FromUser"})
+			c.So(gotBody["agentid"], c.ShouldEqual, 1000002)
+			c.So(gotBody["response_code"], c.ShouldEqual, "ResponseCode")
+			c.So(gotBody["button"], c.ShouldResemble, map[string]any{"replace_name": "已同意"})
+		})
+
+		c.Convey("任务卡片事件不能用来更新模板卡片", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[taskcard_click]]></Event><EventKey><![CDATA[key111]]></EventKey><TaskId><![CDATA[taskid111]]></TaskId><AgentId>1</AgentId></xml>")
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventTaskCardClick()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetEventKey(), c.ShouldEqual, "key111")
+
+			err = app.UpdateClickedTemplateCardButton(msg, "已同意")
+			c.So(err, c.ShouldNotBeNil)
+			c.So(gotPath, c.ShouldEqual, "")
+		})
+	})
+}
+
+func TestSendMessageWithResult(t *testing.T) {
+	c.Convey("发送消息并取得发送结果", t, func() {
+		var respBody string
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			_, _ = rw.Write([]byte(respBody))
+		}))
+		defer closeFn()
+
+		to := Recipient{UserIDs: []string{"foo", "bar", "baz"}, PartyIDs: []string{"1"}}
+
+		c.Convey("全部成功", func() {
+			respBody = `{"errcode":0,"errmsg":"ok","invaliduser":"","invalidparty":"","invalidtag":"","unlicenseduser":"","msgid":"xxxx","response_code":"xyzxyz"}`
+
+			result, err := app.SendTemplateCardMessageWithResult(&to, TemplateCard{}, nil)
+			c.So(err, c.ShouldBeNil)
+			c.So(result.MsgID, c.ShouldEqual, "xxxx")
+			c.So(result.ResponseCode, c.ShouldEqual, "xyzxyz")
+			c.So(result.HasRejectedRecipients(), c.ShouldBeFalse)
+		})
+
+		c.Convey("部分收件人不合法", func() {
+			respBody = `{"errcode":0,"errmsg":"ok","invaliduser":"bar|baz","invalidparty":"1","invalidtag":"","unlicenseduser":"foo","msgid":"xxxx"}`
+
+			result, err := app.SendTextMessageWithResult(&to, "hello", nil)
+			var pe *SendPartialFailureError
+			c.So(errors.As(err, &pe), c.ShouldBeTrue)
+			c.So(pe.Result, c.ShouldEqual, result)
+			c.So(result.MsgID, c.ShouldEqual, "xxxx")
+			c.So(result.InvalidUsers, c.ShouldResemble, []string{"bar", "baz"})
+			c.So(result.InvalidParties, c.ShouldResemble, []string{"1"})
+			c.So(result.InvalidTags, c.ShouldBeNil)
+			c.So(result.UnlicensedUsers, c.ShouldResemble, []string{"foo"})
+
+			// 不返回发送结果的方法维持原有语义
+			c.So(app.SendTextMessage(&to, "hello", false), c.ShouldBeNil)
+		})
+
+		c.Convey("接口报错", func() {
+			respBody = `{"errcode":81013,"errmsg":"user & party & tag all invalid"}`
+
+			result, err := app.SendTextMessageWithResult(&to, "hello", nil)
+			c.So(result, c.ShouldBeNil)
+			var ce *WorkwxClientError
+			c.So(errors.As(err, &ce), c.ShouldBeTrue)
+			c.So(ce.Code, c.ShouldEqual, 81013)
+		})
+
+		c.Convey("收件人不合法", func() {
+			result, err := app.SendTextMessageWithResult(&Recipient{}, "hello", nil)
+			c.So(result, c.ShouldBeNil)
+			c.So(err, c.ShouldEqual, errInvalidRecipient)
+		})
+	})
+}
+
+func TestRecallMessage(t *testing.T) {
+	c.Convey("撤回应用消息", t, func() {
+		var gotPath string
+		var gotBody map[string]any
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}))
+		defer closeFn()
+
+		err := app.RecallMessage("vcT8gGc-7dFb4bxT35ONjBDz901sLlXPZw1DAMC_Gc26qRpK-AK5sTJkkb0128t")
+		c.So(err, c.ShouldBeNil)
+		c.So(gotPath, c.ShouldEqual, "/cgi-bin/message/recall")
+		c.So(gotBody, c.ShouldResemble, map[string]any{"msgid": "vcT8gGc-7dFb4bxT35ONjBDz901sLlXPZw1DAMC_Gc26qRpK-AK5sTJkkb0128t"})
+	})
+}
+
+func TestUpdateTemplateCard(t *testing.T) {
+	c.Convey("更新模版卡片消息", t, func() {
+		var gotBody map[string]any
+		respBody := `{"errcode":0,"errmsg":"ok","invaliduser":[]}`
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			gotBody = nil
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(respBody))
+		}))
+		defer closeFn()
+
+		c.Convey("更新全部收件人的按钮", func() {
+			err := app.UpdateTemplateCard(&TemplateCardUpdateMessage{
+				AtAll:        1,
+				ResponseCode: "code1",
+				Button:       TemplateCardReplaceButton{ReplaceName: "已同意"},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(gotBody, c.ShouldResemble, map[string]any{
+				"agentid":       float64(1000002),
+				"atall":         float64(1),
+				"response_code": "code1",
+				"button":        map[string]any{"replace_name": "已同意"},
+			})
+		})
+
+		c.Convey("更新指定部门、标签的整张卡片", func() {
+			err := app.UpdateTemplateCard(&TemplateCardUpdateMessage{
+				PartyIDs:     []int64{2},
+				TagIDs:       []int32{3},
+				ResponseCode: "code2",
+				TemplateCard: TemplateCard{
+					CardType:  CardTypeTextNotice,
+					MainTitle: &MainTitle{Title: "审批已通过"},
+				},
+				ReplaceText: "已处理",
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(gotBody["partyids"], c.ShouldResemble, []any{float64(2)})
+			c.So(gotBody["tagids"], c.ShouldResemble, []any{float64(3)})
+			c.So(gotBody["replace_text"], c.ShouldEqual, "已处理")
+			c.So(gotBody, c.ShouldNotContainKey, "button")
+			c.So(gotBody, c.ShouldNotContainKey, "userids")
+			card, _ := gotBody["template_card"].(map[string]any)
+			c.So(card["card_type"], c.ShouldEqual, "text_notice")
+		})
+
+		c.Convey("部分成员不合法", func() {
+			respBody = `{"errcode":0,"errmsg":"ok","invaliduser":["foo"]}`
+
+			err := app.UpdateTemplateCard(&TemplateCardUpdateMessage{
+				UserIDs:      []string{"foo", "bar"},
+				ResponseCode: "code3",
+				Button:       TemplateCardReplaceButton{ReplaceName: "已拒绝"},
+			})
+			var pe *SendPartialFailureError
+			c.So(errors.As(err, &pe), c.ShouldBeTrue)
+			c.So(pe.Result.InvalidUsers, c.ShouldResemble, []string{"foo"})
+			c.So(gotBody["userids"], c.ShouldResemble, []any{"foo", "bar"})
+
+			err = app.UpdateTemplateCardButton([]string{"foo", "bar"}, "code4", "已拒绝")
+			c.So(errors.As(err, &pe), c.ShouldBeTrue)
+			c.So(pe.Result.InvalidUsers, c.ShouldResemble, []string{"foo"})
+			c.So(gotBody["response_code"], c.ShouldEqual, "code4")
+		})
+	})
+}
+
+func TestSendOptions(t *testing.T) {
+	c.Convey("发送消息的可选参数", t, func() {
+		var gotBody map[string]any
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			gotBody = nil
+			_ = json.Unmarshal(body, &gotBody)
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","msgid":"m1"}`))
+		}))
+		defer closeFn()
+
+		to := Recipient{UserIDs: []string{"foo"}}
+
+		c.Convey("id 转译与重复消息检查", func() {
+			_, err := app.SendTextMessageWithResult(&to, "$userName=foo$", &SendOptions{
+				EnableIDTrans:          true,
+				EnableDuplicateCheck:   true,
+				DuplicateCheckInterval: 10 * time.Minute,
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(gotBody["enable_id_trans"], c.ShouldEqual, 1)
+			c.So(gotBody["enable_duplicate_check"], c.ShouldEqual, 1)
+			c.So(gotBody["duplicate_check_interval"], c.ShouldEqual, 600)
+		})
+
+		c.Convey("默认不带可选参数", func() {
+			_, err := app.SendTextMessageWithResult(&to, "x", nil)
+			c.So(err, c.ShouldBeNil)
+			c.So(gotBody, c.ShouldNotContainKey, "enable_id_trans")
+			c.So(gotBody, c.ShouldNotContainKey, "enable_duplicate_check")
+			c.So(gotBody, c.ShouldNotContainKey, "duplicate_check_interval")
+		})
+
+		c.Convey("不合法的可选参数", func() {
+			_, err := app.SendTextMessageWithResult(&Recipient{ChatID: "chat1"}, "x", &SendOptions{EnableDuplicateCheck: true})
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+
+			_, err = app.SendTextMessageWithResult(&to, "x", &SendOptions{
+				EnableDuplicateCheck:   true,
+				DuplicateCheckInterval: 5 * time.Hour,
+			})
+			c.So(err, c.ShouldEqual, ErrInvalidDuplicateCheckInterval)
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+
+			_, err = app.SendTextMessageWithResult(&to, "x", &SendOptions{DuplicateCheckInterval: 10 * time.Minute})
+			c.So(errors.Is(err, ErrInvalidMessage), c.ShouldBeTrue)
+
+			_, err = app.SendTextMessageWithResult(&Recipient{ChatID: "chat1"}, "x", &SendOptions{DuplicateCheckInterval: 10 * time.Minute})
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+
+			_, err = NewWebhookClient("k").Send(context.Background(), nil, TextMessage{Content: "x"}, &SendOptions{EnableIDTrans: true})
+			c.So(errors.Is(err, ErrMessageNotSupported), c.ShouldBeTrue)
+			c.So(gotBody, c.ShouldBeNil)
+		})
+	})
+}
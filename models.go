@@ -91,6 +91,20 @@ type respJSAPITicket struct {
 	ExpiresInSecs int64  `json:"expires_in"`
 }
 
+type reqGetCallbackIP struct{}
+
+var _ urlValuer = reqGetCallbackIP{}
+
+func (x reqGetCallbackIP) intoURLValues() url.Values {
+	return url.Values{}
+}
+
+type respGetCallbackIP struct {
+	respCommon
+
+	IPList []string `json:"ip_list"`
+}
+
 // reqMessage 消息发送请求
 type reqMessage struct {
 	ToUser   []string
@@ -101,8 +115,12 @@ type reqMessage struct {
 	OpenKfID string
 	Code     string
 	MsgType  string
-	Content  map[string]any
+	Content  any
 	IsSafe   bool
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int64
 }
 
 var _ bodyer = reqMessage{}
@@ -119,13 +137,18 @@ func (x reqMessage) intoBody() ([]byte, error) {
 		"agentid": x.AgentID,
 		"safe":    safeInt,
 	}
+	if x.EnableIDTrans {
+		obj["enable_id_trans"] = 1
+	}
+	if x.EnableDuplicateCheck {
+		obj["enable_duplicate_check"] = 1
+		if x.DuplicateCheckInterval > 0 {
+			obj["duplicate_check_interval"] = x.DuplicateCheckInterval
+		}
+	}
 
 	// msgtype polymorphism
-	if x.MsgType != "template_card" {
-		obj[x.MsgType] = x.Content
-	} else {
-		obj[x.MsgType] = x.Content["template_card"]
-	}
+	obj[x.MsgType] = x.Content
 
 	// 复用这个结构体，因为是 package-private 的所以这么做没风险
 	if x.ChatID != "" {
@@ -149,9 +172,86 @@ func (x reqMessage) intoBody() ([]byte, error) {
 type respMessageSend struct {
 	respCommon
 
-	InvalidUsers   string `json:"invaliduser"`
-	InvalidParties string `json:"invalidparty"`
-	InvalidTags    string `json:"invalidtag"`
+	InvalidUsers    string `json:"invaliduser"`
+	InvalidParties  string `json:"invalidparty"`
+	InvalidTags     string `json:"invalidtag"`
+	UnlicensedUsers string `json:"unlicenseduser"`
+	MsgID           string `json:"msgid"`
+	ResponseCode    string `json:"response_code"`
+}
+
+// reqMessageUpdateTemplateCard 更新模版卡片消息请求
+type reqMessageUpdateTemplateCard struct {
+	UserIDs      []string
+	PartyIDs     []int64
+	TagIDs       []int32
+	AtAll        bool
+	AgentID      int64
+	ResponseCode string
+	// TemplateCard 不为 nil 时更新整张卡片，否则仅更新按钮
+	TemplateCard *TemplateCard
+	ReplaceText  string
+	ReplaceName  string
+}
+
+var _ bodyer = reqMessageUpdateTemplateCard{}
+
+func (x reqMessageUpdateTemplateCard) intoBody() ([]byte, error) {
+	obj := map[string]any{
+		"agentid":       x.AgentID,
+		"response_code": x.ResponseCode,
+	}
+	if len(x.UserIDs) > 0 {
+		obj["userids"] = x.UserIDs
+	}
+	if len(x.PartyIDs) > 0 {
+		obj["partyids"] = x.PartyIDs
+	}
+	if len(x.TagIDs) > 0 {
+		obj["tagids"] = x.TagIDs
+	}
+	if x.AtAll {
+		obj["atall"] = 1
+	}
+	if x.TemplateCard != nil {
+		obj["template_card"] = x.TemplateCard
+		if x.ReplaceText != "" {
+			obj["replace_text"] = x.ReplaceText
+		}
+	} else {
+		obj["button"] = map[string]any{
+			"replace_name": x.ReplaceName,
+		}
+	}
+
+	return marshalIntoJSONBody(obj)
+}
+
+// respMessageUpdateTemplateCard 更新模版卡片消息响应
+type respMessageUpdateTemplateCard struct {
+	respCommon
+
+	InvalidUsers []string `json:"invaliduser"`
+}
+
+// reqMessageRecall 撤回应用消息请求
+type reqMessageRecall struct {
+	MsgID string
+}
+
+var _ bodyer = reqMessageRecall{}
+
+func (x reqMessageRecall) intoBody() ([]byte, error) {
+	obj := map[string]any{
+		"msgid": x.MsgID,
+	}
+
+	return marshalIntoJSONBody(obj)
+}
+
+// respMessageRecall 撤回应用消息响应
+type respMessageRecall struct {
+	respCommon
 }
 
 type reqUserGet struct {
@@ -1346,17 +1446,25 @@ type TemplateCard struct {
 	SubmitButton *SubmitButton `json:"submit_button,omitempty"`
 }
 
+// TemplateCardUpdateMessage 更新模版卡片消息
+//
+// TemplateCard 设置了 CardType 时更新整张卡片，否则仅将按钮更新为 Button 所示的不可点击状态。
+// AtAll 为 1 时更新该消息全部收件人的卡片，否则仅更新 UserIDs、PartyIDs、TagIDs 所指定收件人的卡片。
 type TemplateCardUpdateMessage struct {
-	UserIDs      []string `json:"userids" validate:"omitempty,max=100"`
-	PartyIDs     []int64  `json:"partyids" validate:"omitempty,max=100"`
-	TagIDs       []int32  `json:"tagids" validate:"omitempty,max=100"`
-	AtAll        int      `json:"atall,omitempty"`
-	ResponseCode string   `json:"response_code"`
-	Button       struct {
-		ReplaceName string `json:"replace_name"`
-	} `json:"button" validate:"required_without=TemplateCard"`
-	TemplateCard TemplateCard `json:"template_card" validate:"required_without=Button"`
-	ReplaceText  string       `json:"replace_text,omitempty"`
+	UserIDs      []string                  `json:"userids" validate:"omitempty,max=100"`
+	PartyIDs     []int64                   `json:"partyids" validate:"omitempty,max=100"`
+	TagIDs       []int32                   `json:"tagids" validate:"omitempty,max=100"`
+	AtAll        int                       `json:"atall,omitempty"`
+	ResponseCode string                    `json:"response_code"`
+	Button       TemplateCardReplaceButton `json:"button" validate:"required_without=TemplateCard"`
+	TemplateCard TemplateCard              `json:"template_card" validate:"required_without=Button"`
+	ReplaceText  string                    `json:"replace_text,omitempty"`
+}
+
+// TemplateCardReplaceButton 更新后的卡片按钮
+type TemplateCardReplaceButton struct {
+	// ReplaceName 按钮更新后显示的文案
+	ReplaceName string `json:"replace_name"`
 }
 
 type reqTransferCustomer struct {
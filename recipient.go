@@ -1,5 +1,9 @@
 package workwx
 
+import (
+	"errors"
+)
+
 // Recipient 消息收件人定义
 type Recipient struct {
 	// UserIDs 成员ID列表（消息接收者），最多支持1000个
@@ -25,6 +29,63 @@ func (x *Recipient) isIndividualTargetsEmpty() bool {
 	return len(x.UserIDs) == 0 && len(x.PartyIDs) == 0 && len(x.TagIDs) == 0
 }
 
+// 【发送应用消息】单次请求的收件人数量上限
+const (
+	maxMessageSendUsers   = 1000
+	maxMessageSendParties = 100
+	maxMessageSendTags    = 100
+)
+
+// exceedsMessageSendLimits 收件人数量是否超出【发送应用消息】单次请求的上限
+func (x *Recipient) exceedsMessageSendLimits() bool {
+	return len(x.UserIDs) > maxMessageSendUsers ||
+		len(x.PartyIDs) > maxMessageSendParties ||
+		len(x.TagIDs) > maxMessageSendTags
+}
+
+// isOversizedForMessageSend 本结构体是否仅因收件人数量超出上限，而对【发送应用消息】请求无效
+func (x *Recipient) isOversizedForMessageSend() bool {
+	return x.OpenKfID == "" && x.Code == "" && x.ChatID == "" && x.exceedsMessageSendLimits()
+}
+
+// splitForMessageSend 将超出上限的收件人拆分为若干批，每批均满足【发送应用消息】的数量上限
+//
+// 仅拆分 UserIDs、PartyIDs、TagIDs，其余字段须为空。
+func (x *Recipient) splitForMessageSend() []Recipient {
+	n := max(
+		chunkCount(len(x.UserIDs), maxMessageSendUsers),
+		chunkCount(len(x.PartyIDs), maxMessageSendParties),
+		chunkCount(len(x.TagIDs), maxMessageSendTags),
+	)
+
+	result := make([]Recipient, n)
+	for i := range result {
+		result[i] = Recipient{
+			UserIDs:  chunkAt(x.UserIDs, i, maxMessageSendUsers),
+			PartyIDs: chunkAt(x.PartyIDs, i, maxMessageSendParties),
+			TagIDs:   chunkAt(x.TagIDs, i, maxMessageSendTags),
+		}
+	}
+	return result
+}
+
+func chunkCount(n int, size int) int {
+	return (n + size - 1) / size
+}
+
+func chunkAt(s []string, i int, size int) []string {
+	lo := i * size
+	if lo >= len(s) {
+		return nil
+	}
+	return s[lo:min(lo+size, len(s))]
+}
+
+// isEmpty 是否未设置任何收件人
+func (x *Recipient) isEmpty() bool {
+	return x.isIndividualTargetsEmpty() && x.ChatID == "" && x.OpenKfID == "" && x.Code == ""
+}
+
 // isValidForMessageSend 本结构体是否对【发送应用消息】请求有效
 func (x *Recipient) isValidForMessageSend() bool {
 	if x.OpenKfID != "" {
@@ -47,7 +108,7 @@ func (x *Recipient) isValidForMessageSend() bool {
 		return false
 	}
 
-	if len(x.UserIDs) > 1000 || len(x.PartyIDs) > 100 || len(x.TagIDs) > 100 {
+	if x.exceedsMessageSendLimits() {
 		// 见字段注释
 		return false
 	}
@@ -92,3 +153,21 @@ func (x *Recipient) isValidForKfSend() bool {
 func (x *Recipient) isValidForKfOnEventSend() bool {
 	return x.Code != ""
 }
+
+var errInvalidRecipient = errors.New("recipient invalid for message sending")
+
+// sendChannel 按所设置的字段，确定本结构体适用的消息发送渠道
+func (x *Recipient) sendChannel() (SendChannel, error) {
+	switch {
+	case x.isValidForMessageSend():
+		return SendChannelApp, nil
+	case x.isValidForAppchatSend():
+		return SendChannelAppchat, nil
+	case x.isValidForKfSend():
+		return SendChannelKf, nil
+	case x.isValidForKfOnEventSend():
+		return SendChannelKfOnEvent, nil
+	default:
+		return 0, errInvalidRecipient
+	}
+}
@@ -147,3 +147,33 @@ func TestRecipientValidation(t *testing.T) {
 		})
 	})
 }
+
+func TestRecipientSplitForMessageSend(t *testing.T) {
+	c.Convey("拆分超出上限的收件人", t, func() {
+		users := make([]string, 2001)
+		for i := range users {
+			users[i] = fmt.Sprintf("u%d", i)
+		}
+		parties := make([]string, 150)
+		for i := range parties {
+			parties[i] = fmt.Sprintf("%d", i)
+		}
+		a := Recipient{UserIDs: users, PartyIDs: parties, TagIDs: []string{"t"}}
+		c.So(a.isOversizedForMessageSend(), c.ShouldBeTrue)
+
+		batches := a.splitForMessageSend()
+		c.So(batches, c.ShouldHaveLength, 3)
+		c.So(batches[0].UserIDs, c.ShouldResemble, users[:1000])
+		c.So(batches[0].PartyIDs, c.ShouldResemble, parties[:100])
+		c.So(batches[0].TagIDs, c.ShouldResemble, []string{"t"})
+		c.So(batches[1].PartyIDs, c.ShouldResemble, parties[100:])
+		c.So(batches[1].TagIDs, c.ShouldBeNil)
+		c.So(batches[2].UserIDs, c.ShouldResemble, users[2000:])
+		c.So(batches[2].PartyIDs, c.ShouldBeNil)
+		for _, b := range batches {
+			c.So(b.isValidForMessageSend(), c.ShouldBeTrue)
+		}
+
+		c.So((&Recipient{ChatID: "x", UserIDs: users}).isOversizedForMessageSend(), c.ShouldBeFalse)
+	})
+}
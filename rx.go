@@ -1,7 +1,9 @@
 package workwx
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
 	"github.com/EnxZhou/go-workwx/internal/lowlevel/httpapi"
@@ -13,23 +15,167 @@ type RxMessageHandler interface {
 	OnIncomingMessage(msg *RxMessage) error
 }
 
+// RxMessageReplier 可被动回复消息的接收消息接口。
+//
+// 如果传给 NewHTTPHandler 的 RxMessageHandler 同时实现了本接口，则收到消息时会
+// 改为调用 OnIncomingMessageWithReply，其返回的 RxReply 将被加密、签名后直接作为
+// 回调请求的响应返回给企业微信，省去一次主动发消息的 API 调用。
+type RxMessageReplier interface {
+	// OnIncomingMessageWithReply 一条消息到来时的回调，返回 nil 的 RxReply 表示不回复。
+	OnIncomingMessageWithReply(msg *RxMessage) (RxReply, error)
+}
+
+// RxMessageHandlerFunc 将函数适配为 RxMessageHandler，同时也实现了 RxMessageReplier
+//
+// 返回 nil 的 RxReply 表示不回复。
+type RxMessageHandlerFunc func(msg *RxMessage) (RxReply, error)
+
+var _ RxMessageHandler = RxMessageHandlerFunc(nil)
+var _ RxMessageReplier = RxMessageHandlerFunc(nil)
+
+// OnIncomingMessage 一条消息到来时的回调。
+func (f RxMessageHandlerFunc) OnIncomingMessage(msg *RxMessage) error {
+	_, err := f(msg)
+	return err
+}
+
+// OnIncomingMessageWithReply 一条消息到来时的回调。
+func (f RxMessageHandlerFunc) OnIncomingMessageWithReply(msg *RxMessage) (RxReply, error) {
+	return f(msg)
+}
+
+// DispatchRxMessage 将消息交给 h 处理
+//
+// 如果 h 实现了 RxMessageReplier 则调用 OnIncomingMessageWithReply，否则调用
+// OnIncomingMessage。包装其他 RxMessageHandler 时应使用本函数，以免丢失被动回复。
+func DispatchRxMessage(h RxMessageHandler, msg *RxMessage) (RxReply, error) {
+	if replier, ok := h.(RxMessageReplier); ok {
+		return replier.OnIncomingMessageWithReply(msg)
+	}
+
+	return nil, h.OnIncomingMessage(msg)
+}
+
 type lowlevelEnvelopeHandler struct {
 	highlevelHandler RxMessageHandler
+	opts             httpHandlerOptions
+	async            *rxAsyncDispatcher
 }
 
 var _ httpapi.EnvelopeHandler = (*lowlevelEnvelopeHandler)(nil)
 
-func (h *lowlevelEnvelopeHandler) OnIncomingEnvelope(rx envelope.Envelope) error {
+func (h *lowlevelEnvelopeHandler) OnIncomingEnvelope(
+	ctx context.Context,
+	rx envelope.Envelope,
+) ([]byte, error) {
+	msg, reply, err := h.handleEnvelope(ctx, rx)
+	if err != nil {
+		return nil, h.onError(msg, err)
+	}
+
+	return reply, nil
+}
+
+// handleEnvelope 解析并处理消息，解析失败时返回的消息为 nil
+func (h *lowlevelEnvelopeHandler) handleEnvelope(
+	ctx context.Context,
+	rx envelope.Envelope,
+) (*RxMessage, []byte, error) {
 	msg, err := fromEnvelope(rx.Msg)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	msg.ctx = ctx
+	msg.credentialID = rx.CredentialID
+
+	store := h.opts.DedupStore
+	if store == nil {
+		reply, err := h.process(msg)
+		return msg, reply, err
+	}
+
+	key := msg.DedupKey()
+	seen, err := store.MarkSeen(key, h.opts.DedupTTL)
+	if err != nil {
+		return msg, nil, err
+	}
+	replyStore, canReply := store.(RxDedupReplyStore)
+	if seen {
+		// 重试推送，已经（或正在）处理过了；首次处理有被动回复的话，再回复一次
+		if !canReply {
+			return msg, nil, nil
+		}
+		reply, err := replyStore.LoadReply(key)
+		return msg, reply, err
+	}
+
+	reply, err := h.process(msg)
+	if err != nil {
+		// 处理失败，让企业微信的重试能被再次处理
+		_ = store.Forget(key)
+		return msg, nil, err
+	}
+
+	if reply != nil && canReply {
+		// 记不住只是重试时收不到回复，不影响本次响应
+		_ = replyStore.SaveReply(key, reply, h.opts.DedupTTL)
+	}
+
+	return msg, reply, nil
+}
+
+// onError 报告错误，并按策略决定是否让企业微信重试
+func (h *lowlevelEnvelopeHandler) onError(msg *RxMessage, err error) error {
+	if h.opts.ErrorHook != nil {
+		h.opts.ErrorHook(msg, err)
+	}
+
+	if h.opts.ErrorPolicy != nil && h.opts.ErrorPolicy(msg, err) == RxErrorDrop {
+		return nil
+	}
+
+	return err
+}
+
+// onAsyncError 报告异步处理中的错误，每个错误只报告一次
+func (h *lowlevelEnvelopeHandler) onAsyncError(msg *RxMessage, err error) {
+	// 兼容只设置了 AsyncConfig.OnError 的用法
+	if h.opts.ErrorHook == nil && h.opts.Async.OnError != nil {
+		h.opts.Async.OnError(msg, err)
+		return
+	}
+
+	// 已经响应过了，错误策略无从谈起
+	_ = h.onError(msg, err)
+}
+
+// process 同步处理消息，或在异步模式下将其入队
+func (h *lowlevelEnvelopeHandler) process(msg *RxMessage) ([]byte, error) {
+	if h.async != nil {
+		// 请求的上下文在响应之后就被取消了
+		msg.ctx = context.WithoutCancel(msg.Context())
+		return nil, h.async.enqueue(msg)
+	}
+
+	return h.dispatch(msg)
+}
+
+func (h *lowlevelEnvelopeHandler) dispatch(msg *RxMessage) ([]byte, error) {
+	reply, err := dispatchRecovering(h.highlevelHandler, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply == nil {
+		return nil, nil
 	}
 
-	return h.highlevelHandler.OnIncomingMessage(msg)
+	return marshalRxReply(msg, reply, time.Now())
 }
 
 type HTTPHandler struct {
 	inner *httpapi.LowlevelHandler
+	async *rxAsyncDispatcher
 }
 
 var _ http.Handler = (*HTTPHandler)(nil)
@@ -38,18 +184,64 @@ func NewHTTPHandler(
 	token string,
 	encodingAESKey string,
 	rxMessageHandler RxMessageHandler,
+	opts ...HTTPHandlerOption,
 ) (*HTTPHandler, error) {
+	creds := []CallbackCredential{
+		{
+			ID:             "",
+			Token:          token,
+			EncodingAESKey: encodingAESKey,
+		},
+	}
+
+	return NewHTTPHandlerWithCredentials(creds, rxMessageHandler, opts...)
+}
+
+// NewHTTPHandlerWithCredentials 构造一个接受多组回调凭据的 HTTPHandler
+//
+// 收到回调时按 creds 的顺序逐一尝试验签与解密，用于在管理后台轮换 Token 与
+// EncodingAESKey 期间，继续接受仍以旧凭据签名、加密的回调。匹配到的凭据 ID
+// 可通过 RxMessage.CredentialID 取得。
+func NewHTTPHandlerWithCredentials(
+	creds []CallbackCredential,
+	rxMessageHandler RxMessageHandler,
+	opts ...HTTPHandlerOption,
+) (*HTTPHandler, error) {
+	optionsObj := defaultHTTPHandlerOptions()
+
+	for _, o := range opts {
+		o.applyTo(&optionsObj)
+	}
+
 	lleh := &lowlevelEnvelopeHandler{
-		highlevelHandler: rxMessageHandler,
+		highlevelHandler: ChainRxMiddlewares(rxMessageHandler, optionsObj.Middlewares...),
+		opts:             optionsObj,
 	}
 
-	llHandler, err := httpapi.NewLowlevelHandler(token, encodingAESKey, lleh)
+	llHandler, err := httpapi.NewLowlevelHandlerWithCredentials(
+		intoLowlevelCredentials(creds),
+		lleh,
+		optionsObj.processorOptions()...,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	optionsObj.applyToLowlevel(llHandler)
+
+	if optionsObj.Async != nil {
+		cfg := *optionsObj.Async
+		cfg.OnError = lleh.onAsyncError
+		lleh.async = newRxAsyncDispatcher(cfg, func(msg *RxMessage) error {
+			// 异步模式下已经响应过了，被动回复无从谈起
+			_, err := lleh.dispatch(msg)
+			return err
+		})
+	}
+
 	obj := HTTPHandler{
 		inner: llHandler,
+		async: lleh.async,
 	}
 
 	return &obj, nil
@@ -58,3 +250,16 @@ func NewHTTPHandler(
 func (h *HTTPHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	h.inner.ServeHTTP(rw, r)
 }
+
+// Shutdown 停止接收回调并等待异步处理队列排空
+//
+// 仅在开启了异步处理（WithAsync）时有实际作用。关闭后收到的回调请求会以 500
+// 失败。如果 ctx 在队列排空前结束，则返回 ctx.Err()，剩余消息仍会在后台继续处理。
+// 请先停止 HTTP 服务（如 http.Server.Shutdown）再调用本方法。
+func (h *HTTPHandler) Shutdown(ctx context.Context) error {
+	if h.async == nil {
+		return nil
+	}
+
+	return h.async.shutdown(ctx)
+}
@@ -0,0 +1,209 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncConfig 异步处理回调的配置
+//
+// 开启异步处理后，回调请求在解密、验签通过后立即返回 200，消息随后交由后台
+// worker 池处理，避免处理耗时过长导致企业微信超时重试。
+//
+// NOTE: 异步模式下无法被动回复，RxMessageReplier 返回的 RxReply 会被忽略。
+type AsyncConfig struct {
+	// Workers 并发处理消息的 worker 数量，默认为 DefaultAsyncWorkers
+	Workers int
+	// QueueSize 每个 worker 的待处理队列长度，默认为 DefaultAsyncQueueSize
+	QueueSize int
+	// EnqueueTimeout 队列已满时等待入队的最长时间
+	//
+	// 超时后回调请求以 500 失败，由企业微信稍后重试。为零则不等待。
+	EnqueueTimeout time.Duration
+	// KeyFunc 计算消息的分派键，键相同的消息总是由同一 worker 按到达顺序处理
+	//
+	// 为 nil 则不保证任何顺序，消息轮流分派给各 worker。
+	// 可以使用 RxMessageKeyByExternalUserID 等现成实现。
+	KeyFunc func(msg *RxMessage) string
+	// OnError 消息处理失败（返回错误或 panic）时的回调，可以为 nil
+	//
+	// Deprecated: 请使用 WithErrorHook，异步模式下的处理失败同样会报告给它。
+	// 设置了 ErrorHook 时不再调用 OnError，以免同一错误被报告两次。
+	OnError func(msg *RxMessage, err error)
+}
+
+// DefaultAsyncWorkers 异步处理回调默认的 worker 数量
+const DefaultAsyncWorkers = 8
+
+// DefaultAsyncQueueSize 异步处理回调默认的每 worker 队列长度
+const DefaultAsyncQueueSize = 64
+
+// ErrAsyncQueueFull 异步处理队列已满
+var ErrAsyncQueueFull = errors.New("go-workwx: async callback queue is full")
+
+// ErrHandlerShutdown 回调 handler 已经关闭
+var ErrHandlerShutdown = errors.New("go-workwx: callback handler is shut down")
+
+// RxMessageKeyByExternalUserID 按外部联系人分派消息，可用作 AsyncConfig.KeyFunc
+//
+// 对不涉及外部联系人的消息，退化为按 FromUserID 分派。
+func RxMessageKeyByExternalUserID(msg *RxMessage) string {
+	if x, ok := msg.extras.(interface{ GetExternalUserID() string }); ok {
+		if id := x.GetExternalUserID(); id != "" {
+			return id
+		}
+	}
+
+	return msg.FromUserID
+}
+
+// RxMessageKeyByFromUserID 按发送者分派消息，可用作 AsyncConfig.KeyFunc
+func RxMessageKeyByFromUserID(msg *RxMessage) string {
+	return msg.FromUserID
+}
+
+type rxAsyncDispatcher struct {
+	cfg    AsyncConfig
+	handle func(msg *RxMessage) error
+
+	queues []chan *RxMessage
+	next   atomic.Uint64
+	wg     sync.WaitGroup
+
+	// mu 保护 closed：入队时持读锁检查并登记到 senders，关闭时持写锁置位。
+	// 等待入队不持锁，关闭时由 done 唤醒；queues 待 senders 全部退出后才关闭。
+	mu      sync.RWMutex
+	closed  bool
+	done    chan struct{}
+	senders sync.WaitGroup
+}
+
+func newRxAsyncDispatcher(cfg AsyncConfig, handle func(msg *RxMessage) error) *rxAsyncDispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultAsyncWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultAsyncQueueSize
+	}
+
+	d := &rxAsyncDispatcher{
+		cfg:    cfg,
+		handle: handle,
+		queues: make([]chan *RxMessage, cfg.Workers),
+		done:   make(chan struct{}),
+	}
+
+	for i := range d.queues {
+		q := make(chan *RxMessage, cfg.QueueSize)
+		d.queues[i] = q
+
+		d.wg.Add(1)
+		go d.worker(q)
+	}
+
+	return d
+}
+
+func (d *rxAsyncDispatcher) pickQueue(msg *RxMessage) chan *RxMessage {
+	n := uint64(len(d.queues))
+
+	if d.cfg.KeyFunc == nil {
+		return d.queues[d.next.Add(1)%n]
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(d.cfg.KeyFunc(msg)))
+	return d.queues[h.Sum64()%n]
+}
+
+func (d *rxAsyncDispatcher) enqueue(msg *RxMessage) error {
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return ErrHandlerShutdown
+	}
+	q := d.pickQueue(msg)
+	d.senders.Add(1)
+	d.mu.RUnlock()
+	defer d.senders.Done()
+
+	select {
+	case q <- msg:
+		return nil
+	default:
+	}
+
+	if d.cfg.EnqueueTimeout <= 0 {
+		return ErrAsyncQueueFull
+	}
+
+	timer := time.NewTimer(d.cfg.EnqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case q <- msg:
+		return nil
+	case <-timer.C:
+		return ErrAsyncQueueFull
+	case <-d.done:
+		return ErrHandlerShutdown
+	}
+}
+
+func (d *rxAsyncDispatcher) worker(q <-chan *RxMessage) {
+	defer d.wg.Done()
+
+	for msg := range q {
+		err := d.handleOne(msg)
+		if err != nil && d.cfg.OnError != nil {
+			d.cfg.OnError(msg, err)
+		}
+	}
+}
+
+func (d *rxAsyncDispatcher) handleOne(msg *RxMessage) (err error) {
+	// 后台 goroutine 中的 panic 会带崩整个进程，这里兜住
+	defer func() {
+		if r := recover(); r != nil {
+			err = &RxPanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return d.handle(msg)
+}
+
+// shutdown 停止接收新消息，并等待已入队的消息处理完毕或 ctx 结束
+func (d *rxAsyncDispatcher) shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.done)
+
+		go func() {
+			// 仍在等待入队的请求会被 done 唤醒，之后再关闭队列就不会有人写入了
+			d.senders.Wait()
+			for _, q := range d.queues {
+				close(q)
+			}
+		}()
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,154 @@
+package workwx
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxAsyncDispatcher(t *testing.T) {
+	c.Convey("异步处理回调", t, func() {
+		c.Convey("同一分派键的消息按到达顺序处理", func() {
+			var mu sync.Mutex
+			seen := make(map[string][]int64)
+
+			d := newRxAsyncDispatcher(AsyncConfig{
+				Workers:        4,
+				EnqueueTimeout: time.Second,
+				KeyFunc:        RxMessageKeyByFromUserID,
+			}, func(msg *RxMessage) error {
+				mu.Lock()
+				defer mu.Unlock()
+				seen[msg.FromUserID] = append(seen[msg.FromUserID], msg.MsgID)
+				return nil
+			})
+
+			for i := range int64(50) {
+				for _, u := range []string{"foo", "bar", "baz"} {
+					err := d.enqueue(&RxMessage{FromUserID: u, MsgID: i})
+					c.So(err, c.ShouldBeNil)
+				}
+			}
+
+			err := d.shutdown(context.Background())
+			c.So(err, c.ShouldBeNil)
+
+			for _, ids := range seen {
+				c.So(len(ids), c.ShouldEqual, 50)
+				for i, id := range ids {
+					c.So(id, c.ShouldEqual, i)
+				}
+			}
+		})
+
+		c.Convey("队列满时拒绝入队，关闭后拒绝入队", func() {
+			block := make(chan struct{})
+			d := newRxAsyncDispatcher(AsyncConfig{
+				Workers:        1,
+				QueueSize:      1,
+				EnqueueTimeout: 10 * time.Millisecond,
+			}, func(*RxMessage) error {
+				<-block
+				return nil
+			})
+
+			// 第一条被 worker 取走阻塞住，第二条占满队列
+			c.So(d.enqueue(&RxMessage{}), c.ShouldBeNil)
+			var err error
+			for range 100 {
+				err = d.enqueue(&RxMessage{})
+				if err != nil {
+					break
+				}
+			}
+			c.So(err, c.ShouldEqual, ErrAsyncQueueFull)
+
+			close(block)
+			c.So(d.shutdown(context.Background()), c.ShouldBeNil)
+			c.So(d.enqueue(&RxMessage{}), c.ShouldEqual, ErrHandlerShutdown)
+		})
+
+		c.Convey("关闭时唤醒等待入队的请求", func() {
+			block := make(chan struct{})
+			d := newRxAsyncDispatcher(AsyncConfig{
+				Workers:        1,
+				QueueSize:      1,
+				EnqueueTimeout: time.Hour,
+			}, func(*RxMessage) error {
+				<-block
+				return nil
+			})
+
+			// 第一条被 worker 取走阻塞住，第二条占满队列，第三条等待入队
+			c.So(d.enqueue(&RxMessage{}), c.ShouldBeNil)
+			for len(d.queues[0]) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+			c.So(d.enqueue(&RxMessage{}), c.ShouldBeNil)
+
+			waiting := make(chan error)
+			go func() { waiting <- d.enqueue(&RxMessage{}) }()
+			time.Sleep(10 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			c.So(d.shutdown(ctx), c.ShouldEqual, context.DeadlineExceeded)
+
+			select {
+			case err := <-waiting:
+				c.So(err, c.ShouldEqual, ErrHandlerShutdown)
+			case <-time.After(time.Second):
+				c.So("enqueue still blocked", c.ShouldBeEmpty)
+			}
+
+			// 已入队的消息仍会处理完
+			close(block)
+			c.So(d.shutdown(context.Background()), c.ShouldBeNil)
+		})
+
+		c.Convey("处理失败与 panic 都报告给 OnError", func() {
+			var mu sync.Mutex
+			var errs []error
+
+			d := newRxAsyncDispatcher(AsyncConfig{
+				Workers: 1,
+				OnError: func(_ *RxMessage, err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, err)
+				},
+			}, func(msg *RxMessage) error {
+				if msg.MsgID == 1 {
+					panic("boom")
+				}
+				return errFakeHandlerFailure
+			})
+
+			c.So(d.enqueue(&RxMessage{MsgID: 1}), c.ShouldBeNil)
+			c.So(d.enqueue(&RxMessage{MsgID: 2}), c.ShouldBeNil)
+			c.So(d.shutdown(context.Background()), c.ShouldBeNil)
+
+			c.So(len(errs), c.ShouldEqual, 2)
+			c.So(errs[0].Error(), c.ShouldContainSubstring, "boom")
+			c.So(errs[1], c.ShouldEqual, errFakeHandlerFailure)
+		})
+
+		c.Convey("排空超时返回 ctx 的错误", func() {
+			block := make(chan struct{})
+			defer close(block)
+
+			d := newRxAsyncDispatcher(AsyncConfig{Workers: 1}, func(*RxMessage) error {
+				<-block
+				return nil
+			})
+			c.So(d.enqueue(&RxMessage{}), c.ShouldBeNil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			c.So(d.shutdown(ctx), c.ShouldEqual, context.DeadlineExceeded)
+		})
+	})
+}
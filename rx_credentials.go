@@ -0,0 +1,42 @@
+package workwx
+
+import (
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/httpapi"
+)
+
+// CallbackCredential 一组回调凭据
+type CallbackCredential struct {
+	// ID 凭据的标识，由调用方自行指定，用于区分匹配到的凭据、停用指定凭据
+	ID string
+	// Token 应用回调配置中的 Token
+	Token string
+	// EncodingAESKey 应用回调配置中的 EncodingAESKey
+	EncodingAESKey string
+}
+
+func intoLowlevelCredentials(creds []CallbackCredential) []httpapi.Credential {
+	result := make([]httpapi.Credential, len(creds))
+	for i, c := range creds {
+		result[i] = httpapi.Credential{
+			ID:             c.ID,
+			Token:          c.Token,
+			EncodingAESKey: c.EncodingAESKey,
+		}
+	}
+
+	return result
+}
+
+// SetCredentials 整体替换回调凭据，立即生效，无需重启
+//
+// creds 不能为空；任何一组凭据无效时返回错误，原有凭据保持不变。
+func (h *HTTPHandler) SetCredentials(creds []CallbackCredential) error {
+	return h.inner.SetCredentials(intoLowlevelCredentials(creds))
+}
+
+// RetireCredential 停用给定 ID 的回调凭据，立即生效，无需重启
+//
+// 找不到该 ID 或该凭据是仅剩的一组时返回错误。
+func (h *HTTPHandler) RetireCredential(id string) error {
+	return h.inner.RetireCredential(id)
+}
@@ -0,0 +1,214 @@
+package workwx
+
+import (
+	"container/list"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDedupTTL 默认的回调去重时间窗口
+//
+// 企业微信对同一回调最多重试 3 次，每次间隔 5 秒，留足余量即可。
+const DefaultDedupTTL = 5 * time.Minute
+
+// RxDedupStore 回调消息去重所用的存储
+//
+// 实现需要是并发安全的。如需在多实例间共享去重状态，可自行基于 Redis 等实现。
+type RxDedupStore interface {
+	// MarkSeen 标记 key 已收到，有效期为 ttl。
+	//
+	// 如果 key 在有效期内已经被标记过，则返回 true。
+	MarkSeen(key string, ttl time.Duration) (bool, error)
+
+	// Forget 撤销对 key 的标记，使后续的重试可以被再次处理。
+	Forget(key string) error
+}
+
+// RxDedupReplyStore 可以记住被动回复的 RxDedupStore
+//
+// 被动回复只能随回调的响应送达。如果去重存储同时实现了本接口，重试推送会得到与
+// 首次处理相同的被动回复；否则重试推送得到的是空响应，首次的响应若未送达，
+// 被动回复就丢失了。
+type RxDedupReplyStore interface {
+	RxDedupStore
+
+	// SaveReply 记住 key 对应的被动回复，有效期为 ttl。
+	SaveReply(key string, reply []byte, ttl time.Duration) error
+
+	// LoadReply 取出 key 对应的被动回复，没有则返回 nil。
+	LoadReply(key string) ([]byte, error)
+}
+
+// DedupKey 返回用于回调去重的键
+//
+// 键以接收方的 CorpID 与 AgentID 开头，多个应用共用同一 RxDedupStore 时互不干扰。
+// 其后普通消息使用 MsgID；事件则使用 FromUserID、SendTime、Event 与 ChangeType 的组合。
+// 由于许多事件的 FromUserID 固定为 sys，同一秒内的多个同类事件（如批量变更成员）
+// 会撞车，因此事件的键还额外带上了消息体的摘要。重试推送的消息体是相同的。
+func (m *RxMessage) DedupKey() string {
+	var sb strings.Builder
+	sb.WriteString(m.toUserName)
+	sb.WriteByte('|')
+	sb.WriteString(strconv.FormatInt(m.AgentID, 10))
+	sb.WriteByte('|')
+
+	if m.MsgID != 0 {
+		sb.WriteString(strconv.FormatInt(m.MsgID, 10))
+		return sb.String()
+	}
+
+	sb.WriteString(m.FromUserID)
+	sb.WriteByte('|')
+	sb.WriteString(strconv.FormatInt(m.SendTime.Unix(), 10))
+	sb.WriteByte('|')
+	sb.WriteString(string(m.Event))
+	sb.WriteByte('|')
+	sb.WriteString(string(m.ChangeType))
+	sb.WriteByte('|')
+	sb.WriteString(strconv.FormatUint(uint64(crc32.ChecksumIEEE(m.raw)), 16))
+	return sb.String()
+}
+
+// DedupKey 返回用于指令回调去重的键
+//
+// 由 SuiteID、AuthCorpID、InfoType、ChangeType、指令时间与消息体的摘要组成。
+func (i *RxSuiteInfo) DedupKey() string {
+	var sb strings.Builder
+	sb.WriteString(i.SuiteID)
+	sb.WriteByte('|')
+	sb.WriteString(i.AuthCorpID)
+	sb.WriteByte('|')
+	sb.WriteString(string(i.InfoType))
+	sb.WriteByte('|')
+	sb.WriteString(string(i.ChangeType))
+	sb.WriteByte('|')
+	sb.WriteString(strconv.FormatInt(i.Time.Unix(), 10))
+	sb.WriteByte('|')
+	sb.WriteString(strconv.FormatUint(uint64(crc32.ChecksumIEEE(i.raw)), 16))
+	return sb.String()
+}
+
+// MemoryDedupStore 基于内存 LRU 的 RxDedupStore 实现
+type MemoryDedupStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+
+	timeSource func() time.Time
+}
+
+type memoryDedupEntry struct {
+	key       string
+	expiresAt time.Time
+	reply     []byte
+}
+
+var _ RxDedupReplyStore = (*MemoryDedupStore)(nil)
+
+// NewMemoryDedupStore 构造一个最多记住 capacity 个键的内存去重存储
+//
+// 超出容量时淘汰最久未被访问的键。
+func NewMemoryDedupStore(capacity int) *MemoryDedupStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &MemoryDedupStore{
+		mu:       sync.Mutex{},
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+
+		timeSource: time.Now,
+	}
+}
+
+// MarkSeen 标记 key 已收到，有效期为 ttl。
+func (s *MemoryDedupStore) MarkSeen(key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeSource()
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryDedupEntry)
+		if now.Before(entry.expiresAt) {
+			s.ll.MoveToFront(el)
+			return true, nil
+		}
+
+		// expired, treat as a fresh one
+		entry.expiresAt = now.Add(ttl)
+		entry.reply = nil
+		s.ll.MoveToFront(el)
+		return false, nil
+	}
+
+	el := s.ll.PushFront(&memoryDedupEntry{key: key, expiresAt: now.Add(ttl)})
+	s.items[key] = el
+
+	for s.ll.Len() > s.capacity {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryDedupEntry).key)
+	}
+
+	return false, nil
+}
+
+// Forget 撤销对 key 的标记。
+func (s *MemoryDedupStore) Forget(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.ll.Remove(el)
+		delete(s.items, key)
+	}
+
+	return nil
+}
+
+// SaveReply 记住 key 对应的被动回复，有效期与 key 的标记相同，ttl 被忽略。
+//
+// key 未被标记或已过期时不做任何事。
+func (s *MemoryDedupStore) SaveReply(key string, reply []byte, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryDedupEntry)
+		if s.timeSource().Before(entry.expiresAt) {
+			entry.reply = reply
+		}
+	}
+
+	return nil
+}
+
+// LoadReply 取出 key 对应的被动回复，没有则返回 nil。
+func (s *MemoryDedupStore) LoadReply(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryDedupEntry)
+		if s.timeSource().Before(entry.expiresAt) {
+			return entry.reply, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Len 返回当前记住的键数量（含已过期但尚未被淘汰的）
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ll.Len()
+}
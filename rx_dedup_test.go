@@ -0,0 +1,179 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+)
+
+type countingRxMessageHandler struct {
+	calls int
+	err   error
+}
+
+func (h *countingRxMessageHandler) OnIncomingMessage(*RxMessage) error {
+	h.calls++
+	return h.err
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	c.Convey("内存 LRU 去重存储", t, func() {
+		now := time.Unix(1583995625, 0)
+		s := NewMemoryDedupStore(2)
+		s.timeSource = func() time.Time { return now }
+
+		c.Convey("有效期内重复标记应该报告已见过", func() {
+			seen, err := s.MarkSeen("a", time.Minute)
+			c.So(err, c.ShouldBeNil)
+			c.So(seen, c.ShouldBeFalse)
+
+			seen, err = s.MarkSeen("a", time.Minute)
+			c.So(err, c.ShouldBeNil)
+			c.So(seen, c.ShouldBeTrue)
+		})
+
+		c.Convey("过期之后应该重新视为新键", func() {
+			_, _ = s.MarkSeen("a", time.Minute)
+			now = now.Add(2 * time.Minute)
+
+			seen, err := s.MarkSeen("a", time.Minute)
+			c.So(err, c.ShouldBeNil)
+			c.So(seen, c.ShouldBeFalse)
+		})
+
+		c.Convey("超出容量时淘汰最久未访问的键", func() {
+			_, _ = s.MarkSeen("a", time.Minute)
+			_, _ = s.MarkSeen("b", time.Minute)
+			_, _ = s.MarkSeen("a", time.Minute)
+			_, _ = s.MarkSeen("c", time.Minute)
+			c.So(s.Len(), c.ShouldEqual, 2)
+
+			seen, _ := s.MarkSeen("b", time.Minute)
+			c.So(seen, c.ShouldBeFalse)
+		})
+
+		c.Convey("被动回复随标记过期", func() {
+			_, _ = s.MarkSeen("a", time.Minute)
+			c.So(s.SaveReply("a", []byte("x"), time.Minute), c.ShouldBeNil)
+			reply, err := s.LoadReply("a")
+			c.So(err, c.ShouldBeNil)
+			c.So(string(reply), c.ShouldEqual, "x")
+
+			now = now.Add(2 * time.Minute)
+			reply, _ = s.LoadReply("a")
+			c.So(reply, c.ShouldBeNil)
+
+			_, _ = s.MarkSeen("a", time.Minute)
+			reply, _ = s.LoadReply("a")
+			c.So(reply, c.ShouldBeNil)
+		})
+
+		c.Convey("Forget 之后可以再次标记", func() {
+			_, _ = s.MarkSeen("a", time.Minute)
+			c.So(s.Forget("a"), c.ShouldBeNil)
+
+			seen, _ := s.MarkSeen("a", time.Minute)
+			c.So(seen, c.ShouldBeFalse)
+		})
+	})
+}
+
+func TestRxMessageDedup(t *testing.T) {
+	c.Convey("回调去重", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+		eventBody1 := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_contact]]></Event><ChangeType><![CDATA[del_external_contact]]></ChangeType><UserID><![CDATA[zhangsan]]></UserID><ExternalUserID><![CDATA[woAJ2GCAAAXtWyujaWJHDDGi0mAAAA]]></ExternalUserID></xml>")
+		eventBody2 := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_contact]]></Event><ChangeType><![CDATA[del_external_contact]]></ChangeType><UserID><![CDATA[lisi]]></UserID><ExternalUserID><![CDATA[woAJ2GCAAAXtWyujaWJHDDGi0mAAAA]]></ExternalUserID></xml>")
+
+		c.Convey("DedupKey", func() {
+			msg, err := fromEnvelope(textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.DedupKey(), c.ShouldEqual, "ww6a112864f8022910|1000002|2018405441")
+
+			ev1, err := fromEnvelope(eventBody1)
+			c.So(err, c.ShouldBeNil)
+			ev2, err := fromEnvelope(eventBody2)
+			c.So(err, c.ShouldBeNil)
+			c.So(ev1.DedupKey(), c.ShouldStartWith, "toUser|0|sys|1403610513|change_external_contact|del_external_contact|")
+			c.So(ev1.DedupKey(), c.ShouldNotEqual, ev2.DedupKey())
+
+			// 同一事件推送给不同企业或应用时不应被当作重复
+			other, err := fromEnvelope(bytes.Replace(eventBody1, []byte("toUser"), []byte("otherCorp"), 1))
+			c.So(err, c.ShouldBeNil)
+			c.So(other.DedupKey(), c.ShouldNotEqual, ev1.DedupKey())
+
+			otherApp, err := fromEnvelope(bytes.Replace(textBody, []byte("<AgentID>1000002"), []byte("<AgentID>1000003"), 1))
+			c.So(err, c.ShouldBeNil)
+			c.So(otherApp.DedupKey(), c.ShouldNotEqual, msg.DedupKey())
+		})
+
+		inner := &countingRxMessageHandler{}
+		h := lowlevelEnvelopeHandler{
+			highlevelHandler: inner,
+			opts: httpHandlerOptions{
+				DedupStore: NewMemoryDedupStore(16),
+				DedupTTL:   DefaultDedupTTL,
+			},
+		}
+
+		c.Convey("重试推送只处理一次", func() {
+			for range 3 {
+				_, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+				c.So(err, c.ShouldBeNil)
+			}
+			c.So(inner.calls, c.ShouldEqual, 1)
+		})
+
+		c.Convey("处理失败的消息允许重试", func() {
+			inner.err = errFakeHandlerFailure
+			_, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+			c.So(err, c.ShouldEqual, errFakeHandlerFailure)
+
+			inner.err = nil
+			_, err = h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+			c.So(err, c.ShouldBeNil)
+			c.So(inner.calls, c.ShouldEqual, 2)
+		})
+
+		c.Convey("重试推送收到同样的被动回复", func() {
+			calls := 0
+			replier := RxMessageHandlerFunc(func(*RxMessage) (RxReply, error) {
+				calls++
+				return &TextReply{Content: "pong"}, nil
+			})
+			h := lowlevelEnvelopeHandler{
+				highlevelHandler: replier,
+				opts: httpHandlerOptions{
+					DedupStore: NewMemoryDedupStore(16),
+					DedupTTL:   DefaultDedupTTL,
+				},
+			}
+
+			first, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+			c.So(err, c.ShouldBeNil)
+			c.So(string(first), c.ShouldContainSubstring, "pong")
+
+			retry, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+			c.So(err, c.ShouldBeNil)
+			c.So(retry, c.ShouldResemble, first)
+			c.So(calls, c.ShouldEqual, 1)
+
+			c.Convey("去重存储记不住回复时，重试推送得到空响应", func() {
+				h.opts.DedupStore = struct{ RxDedupStore }{NewMemoryDedupStore(16)}
+
+				_, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+				c.So(err, c.ShouldBeNil)
+				retry, err := h.OnIncomingEnvelope(context.Background(), envelope.Envelope{Msg: textBody})
+				c.So(err, c.ShouldBeNil)
+				c.So(retry, c.ShouldBeNil)
+			})
+		})
+	})
+}
+
+var errFakeHandlerFailure = errors.New("fake handler failure")
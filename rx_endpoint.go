@@ -0,0 +1,331 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RxEndpointRouting 多应用回调端点的路由方式
+type RxEndpointRouting int
+
+const (
+	// RxEndpointRouteByPath 按请求路径的最后一段路由
+	//
+	// 如 /callback/app1 的路由键为 app1。
+	RxEndpointRouteByPath RxEndpointRouting = iota + 1
+	// RxEndpointRouteByReceiver 按回调包中明文的 ToUserName 与 AgentID 路由
+	//
+	// 路由键由 RxEndpointReceiverKey 构造；先按 CorpID 与 AgentID 查找，找不到再
+	// 只按 CorpID 查找。回调 URL 验证请求不带回调包，届时按签名挑选 Token 相符的应用。
+	RxEndpointRouteByReceiver
+)
+
+// RxEndpointReceiverKey 构造按接收方路由时的路由键
+//
+// agentID 为空表示匹配该企业下的所有应用，如通讯录、客户联系等不带 AgentID 的回调。
+func RxEndpointReceiverKey(corpID string, agentID string) string {
+	if agentID == "" {
+		return corpID
+	}
+
+	return corpID + "/" + agentID
+}
+
+// CallbackApp 多应用回调端点中的一个应用
+type CallbackApp struct {
+	// Key 路由键，含义取决于端点的路由方式
+	Key string
+	// Credentials 该应用的回调凭据
+	Credentials []CallbackCredential
+	// Handler 该应用的消息处理函数
+	Handler RxMessageHandler
+	// Options 该应用的回调 handler 构造参数
+	Options []HTTPHandlerOption
+}
+
+// ErrCallbackNoRoute 回调请求路由不到任何已注册的应用
+var ErrCallbackNoRoute = errors.New("go-workwx: no app registered for callback")
+
+// RxEndpoint 为多个企业、应用服务的单一回调端点
+//
+// 每个应用有自己的回调凭据与 RxMessageHandler，可在运行时增删，无需重启。
+// 路由不到任何应用的请求会以 404 失败。
+type RxEndpoint struct {
+	routing RxEndpointRouting
+
+	mu         sync.RWMutex
+	apps       map[string]*HTTPHandler
+	rejectHook func(r *http.Request, err error)
+}
+
+var _ http.Handler = (*RxEndpoint)(nil)
+
+// NewRxEndpoint 构造一个空的多应用回调端点
+func NewRxEndpoint(routing RxEndpointRouting) *RxEndpoint {
+	return &RxEndpoint{
+		routing:    routing,
+		mu:         sync.RWMutex{},
+		apps:       make(map[string]*HTTPHandler),
+		rejectHook: nil,
+	}
+}
+
+// SetRejectHook 设置路由到应用之前请求被拒绝时的回调，可用于记录日志或报警
+//
+// err 可用 errors.Is 与 ErrCallbackBodyTooLarge、ErrCallbackMalformed、
+// ErrCallbackInvalidSignature、ErrCallbackNoRoute 比较。路由到应用之后的拒绝
+// 由该应用自己的 WithRejectHook 报告。
+func (e *RxEndpoint) SetRejectHook(hook func(r *http.Request, err error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rejectHook = hook
+}
+
+// reject 报告并以给定状态码拒绝请求
+func (e *RxEndpoint) reject(rw http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	e.mu.RLock()
+	hook := e.rejectHook
+	e.mu.RUnlock()
+
+	if hook != nil {
+		hook(r, err)
+	}
+
+	rw.WriteHeader(statusCode)
+}
+
+// Register 以给定路由键注册一个应用的回调 handler，已有的注册会被替换
+func (e *RxEndpoint) Register(key string, h *HTTPHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.apps[key] = h
+}
+
+// RegisterApp 按配置构造并注册一个应用
+func (e *RxEndpoint) RegisterApp(app CallbackApp) error {
+	h, err := NewHTTPHandlerWithCredentials(app.Credentials, app.Handler, app.Options...)
+	if err != nil {
+		return err
+	}
+
+	e.Register(app.Key, h)
+	return nil
+}
+
+// Unregister 注销给定路由键的应用，返回被注销的 handler，没有则返回 nil
+//
+// 如果该应用开启了异步处理，调用方应对返回的 handler 调用 Shutdown。
+func (e *RxEndpoint) Unregister(key string) *HTTPHandler {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	h, ok := e.apps[key]
+	if !ok {
+		return nil
+	}
+
+	delete(e.apps, key)
+	return h
+}
+
+// Lookup 返回给定路由键对应的 handler
+func (e *RxEndpoint) Lookup(key string) (*HTTPHandler, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	h, ok := e.apps[key]
+	return h, ok
+}
+
+// Shutdown 依次关闭所有已注册的应用，返回遇到的所有错误
+func (e *RxEndpoint) Shutdown(ctx context.Context) error {
+	e.mu.RLock()
+	handlers := make([]*HTTPHandler, 0, len(e.apps))
+	for _, h := range e.apps {
+		handlers = append(handlers, h)
+	}
+	e.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *RxEndpoint) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch e.routing {
+	case RxEndpointRouteByPath:
+		e.serveByPath(rw, r)
+	case RxEndpointRouteByReceiver:
+		e.serveByReceiver(rw, r)
+	default:
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (e *RxEndpoint) serveByPath(rw http.ResponseWriter, r *http.Request) {
+	p := strings.TrimRight(r.URL.Path, "/")
+	key := p[strings.LastIndexByte(p, '/')+1:]
+
+	h, ok := e.Lookup(key)
+	if !ok {
+		e.reject(rw, r, http.StatusNotFound, fmt.Errorf("%w: path %s", ErrCallbackNoRoute, r.URL.Path))
+		return
+	}
+
+	h.ServeHTTP(rw, r)
+}
+
+// xmlRxReceiver 回调包中不加密的接收方信息
+type xmlRxReceiver struct {
+	ToUserName string `xml:"ToUserName"`
+	AgentID    string `xml:"AgentID"`
+}
+
+func (e *RxEndpoint) serveByReceiver(rw http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		e.serveEchoTest(rw, r)
+		return
+	}
+
+	// 路由前尚不知道是哪个应用，按各应用中最宽的上限读取；
+	// 路由后由应用自己的 handler 按其上限再检查一遍
+	defer r.Body.Close()
+	src := r.Body
+	if limit := e.peekBodyLimit(); limit > 0 {
+		src = http.MaxBytesReader(rw, r.Body, limit)
+	}
+	body, err := io.ReadAll(src)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			e.reject(rw, r, http.StatusRequestEntityTooLarge, ErrCallbackBodyTooLarge)
+			return
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var rcv xmlRxReceiver
+	err = xml.Unmarshal(body, &rcv)
+	if err != nil {
+		e.reject(rw, r, http.StatusBadRequest, fmt.Errorf("%w: %v", ErrCallbackMalformed, err))
+		return
+	}
+
+	h, ok := e.Lookup(RxEndpointReceiverKey(rcv.ToUserName, rcv.AgentID))
+	if !ok {
+		h, ok = e.Lookup(RxEndpointReceiverKey(rcv.ToUserName, ""))
+	}
+	if !ok {
+		err := fmt.Errorf("%w: receiver %s", ErrCallbackNoRoute, RxEndpointReceiverKey(rcv.ToUserName, rcv.AgentID))
+		e.reject(rw, r, http.StatusNotFound, err)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	h.ServeHTTP(rw, r)
+}
+
+// peekBodyLimit 路由前读取请求体的上限，即各应用上限中最宽者，非正数表示不限制
+func (e *RxEndpoint) peekBodyLimit() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.apps) == 0 {
+		return DefaultMaxCallbackBodySize
+	}
+
+	var limit int64
+	for _, h := range e.apps {
+		n := h.inner.MaxBodySize()
+		if n <= 0 {
+			return 0
+		}
+		limit = max(limit, n)
+	}
+	return limit
+}
+
+// serveEchoTest 按签名挑选应用响应回调 URL 验证请求
+//
+// 只有 Token 相符的应用才会处理该请求，其余应用的 RejectHook 等不会被触发。
+func (e *RxEndpoint) serveEchoTest(rw http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	handlers := make([]*HTTPHandler, 0, len(e.apps))
+	for _, h := range e.apps {
+		if h.inner.MatchesEchoTestSignature(r) {
+			handlers = append(handlers, h)
+		}
+	}
+	e.mu.RUnlock()
+
+	switch len(handlers) {
+	case 0:
+		err := fmt.Errorf("%w: no app matches the signature", ErrCallbackInvalidSignature)
+		e.reject(rw, r, http.StatusNotFound, err)
+	case 1:
+		handlers[0].ServeHTTP(rw, r)
+	default:
+		// 多个应用共用同一 Token 时，以能解密者为准
+		for _, h := range handlers {
+			rec := newBufferedResponseWriter()
+			h.ServeHTTP(rec, r)
+			if rec.status == http.StatusOK {
+				rec.flushTo(rw)
+				return
+			}
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// bufferedResponseWriter 暂存响应以便决定是否采用
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+var _ http.ResponseWriter = (*bufferedResponseWriter)(nil)
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		header: make(http.Header),
+		status: http.StatusOK,
+		body:   bytes.Buffer{},
+	}
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	w.status = statusCode
+}
+
+func (w *bufferedResponseWriter) flushTo(rw http.ResponseWriter) {
+	for k, v := range w.header {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(w.status)
+	_, _ = rw.Write(w.body.Bytes())
+}
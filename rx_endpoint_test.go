@@ -0,0 +1,242 @@
+package workwx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+)
+
+// makeTestCallbackRequest 以给定凭据加密 msg，构造发往 endpoint 的回调请求
+func makeTestCallbackRequest(
+	endpoint string,
+	cred CallbackCredential,
+	corpID string,
+	agentID string,
+	msg []byte,
+) (*http.Request, error) {
+	ep, err := envelope.NewProcessor(cred.Token, cred.EncodingAESKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 出站包与入站包的 Encrypt 与签名算法一致
+	pkt, err := ep.MakeOutgoingEnvelopeWithReceiveID(msg, []byte(corpID))
+	if err != nil {
+		return nil, err
+	}
+	var x struct {
+		Encrypt      string `xml:"Encrypt"`
+		MsgSignature string `xml:"MsgSignature"`
+		Timestamp    int64  `xml:"TimeStamp"`
+		Nonce        string `xml:"Nonce"`
+	}
+	err = xml.Unmarshal(pkt, &x)
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf(
+		"<xml><ToUserName><![CDATA[%s]]></ToUserName><AgentID><![CDATA[%s]]></AgentID><Encrypt><![CDATA[%s]]></Encrypt></xml>",
+		corpID,
+		agentID,
+		x.Encrypt,
+	)
+	url := fmt.Sprintf(
+		"%s?msg_signature=%s&timestamp=%d&nonce=%s",
+		endpoint,
+		x.MsgSignature,
+		x.Timestamp,
+		x.Nonce,
+	)
+
+	return http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(body)))
+}
+
+func TestRxEndpoint(t *testing.T) {
+	c.Convey("多应用回调端点", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+
+		//nolint: gosec  // randomly generated for test purposes only
+		cred1 := CallbackCredential{
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+		//nolint: gosec  // randomly generated for test purposes only
+		cred2 := CallbackCredential{
+			Token:          "QDG6eK3bOvFpBMb2hsSPKBPHXbjoDrqD",
+			EncodingAESKey: "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C",
+		}
+		h1 := &countingRxMessageHandler{}
+		h2 := &countingRxMessageHandler{}
+
+		do := func(e *RxEndpoint, req *http.Request) int {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		c.Convey("按路径路由", func() {
+			e := NewRxEndpoint(RxEndpointRouteByPath)
+			c.So(e.RegisterApp(CallbackApp{Key: "app1", Credentials: []CallbackCredential{cred1}, Handler: h1}), c.ShouldBeNil)
+			c.So(e.RegisterApp(CallbackApp{Key: "app2", Credentials: []CallbackCredential{cred2}, Handler: h2}), c.ShouldBeNil)
+
+			req, err := makeTestCallbackRequest("http://a.b/callback/app2", cred2, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusOK)
+			c.So(h1.calls, c.ShouldEqual, 0)
+			c.So(h2.calls, c.ShouldEqual, 1)
+
+			req, err = makeTestCallbackRequest("http://a.b/callback/app3", cred2, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+
+			c.So(e.Unregister("app2"), c.ShouldNotBeNil)
+			req, err = makeTestCallbackRequest("http://a.b/callback/app2", cred2, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+		})
+
+		c.Convey("按接收方路由", func() {
+			e := NewRxEndpoint(RxEndpointRouteByReceiver)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", "1000002"),
+				Credentials: []CallbackCredential{cred1},
+				Handler:     h1,
+			}), c.ShouldBeNil)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", ""),
+				Credentials: []CallbackCredential{cred2},
+				Handler:     h2,
+			}), c.ShouldBeNil)
+
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred1, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusOK)
+			c.So(h1.calls, c.ShouldEqual, 1)
+
+			// 找不到应用时退回到企业级的注册
+			req, err = makeTestCallbackRequest("http://a.b/callback", cred2, "ww6a112864f8022910", "", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusOK)
+			c.So(h2.calls, c.ShouldEqual, 1)
+
+			req, err = makeTestCallbackRequest("http://a.b/callback", cred1, "wwunknown", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+		})
+
+		c.Convey("按签名挑选应用响应回调 URL 验证", func() {
+			var rejected1, rejected2 int
+			e := NewRxEndpoint(RxEndpointRouteByReceiver)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", "1000002"),
+				Credentials: []CallbackCredential{cred1},
+				Handler:     h1,
+				Options:     []HTTPHandlerOption{WithRejectHook(func(*http.Request, error) { rejected1++ })},
+			}), c.ShouldBeNil)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", ""),
+				Credentials: []CallbackCredential{cred2},
+				Handler:     h2,
+				Options:     []HTTPHandlerOption{WithRejectHook(func(*http.Request, error) { rejected2++ })},
+			}), c.ShouldBeNil)
+
+			// 以 cred1 签名
+			req := httptest.NewRequest(http.MethodGet, "http://a.b/callback?echostr=6KmUQuPVu7UhjyVqRdbo5SfcRqaHvbUlKSHFvBV2ZuR6TIlKsygcfeSd1GDplg1C5KSKr6UPHCaC%2FnIX3ZNt9w%3D%3D&msg_signature=1ba3cb09c0d2c2b3ed6900d37f91a6efae6cb011&timestamp=1583940690&nonce=VHh7ymSeb0jc4lSb", nil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusOK)
+			c.So(rejected1, c.ShouldEqual, 0)
+			c.So(rejected2, c.ShouldEqual, 0)
+
+			req = httptest.NewRequest(http.MethodGet, "http://a.b/callback?echostr=6KmUQuPVu7UhjyVqRdbo5SfcRqaHvbUlKSHFvBV2ZuR6TIlKsygcfeSd1GDplg1C5KSKr6UPHCaC%2FnIX3ZNt9w%3D%3D&msg_signature=1ba3cb09c0d2c2b3ed6900d37f91a6efae6cb010&timestamp=1583940690&nonce=VHh7ymSeb0jc4lSb", nil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+			c.So(rejected1, c.ShouldEqual, 0)
+			c.So(rejected2, c.ShouldEqual, 0)
+		})
+
+		c.Convey("路由前的拒绝报告给端点的 RejectHook", func() {
+			var rejected []error
+			e := NewRxEndpoint(RxEndpointRouteByReceiver)
+			e.SetRejectHook(func(_ *http.Request, err error) {
+				rejected = append(rejected, err)
+			})
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", "1000002"),
+				Credentials: []CallbackCredential{cred1},
+				Handler:     h1,
+				Options:     []HTTPHandlerOption{WithMaxBodySize(1024)},
+			}), c.ShouldBeNil)
+
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred1, "wwunknown", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+			c.So(errors.Is(rejected[0], ErrCallbackNoRoute), c.ShouldBeTrue)
+
+			req = httptest.NewRequest(http.MethodPost, "http://a.b/callback", strings.NewReader("not xml"))
+			c.So(do(e, req), c.ShouldEqual, http.StatusBadRequest)
+			c.So(errors.Is(rejected[1], ErrCallbackMalformed), c.ShouldBeTrue)
+
+			req = httptest.NewRequest(http.MethodPost, "http://a.b/callback", strings.NewReader(strings.Repeat("x", 2048)))
+			c.So(do(e, req), c.ShouldEqual, http.StatusRequestEntityTooLarge)
+			c.So(errors.Is(rejected[2], ErrCallbackBodyTooLarge), c.ShouldBeTrue)
+
+			req = httptest.NewRequest(http.MethodGet, "http://a.b/callback?echostr=6KmUQuPVu7UhjyVqRdbo5SfcRqaHvbUlKSHFvBV2ZuR6TIlKsygcfeSd1GDplg1C5KSKr6UPHCaC%2FnIX3ZNt9w%3D%3D&msg_signature=1ba3cb09c0d2c2b3ed6900d37f91a6efae6cb010&timestamp=1583940690&nonce=VHh7ymSeb0jc4lSb", nil)
+			c.So(do(e, req), c.ShouldEqual, http.StatusNotFound)
+			c.So(errors.Is(rejected[3], ErrCallbackInvalidSignature), c.ShouldBeTrue)
+
+			byPath := NewRxEndpoint(RxEndpointRouteByPath)
+			byPath.SetRejectHook(func(_ *http.Request, err error) {
+				rejected = append(rejected, err)
+			})
+			req, err = makeTestCallbackRequest("http://a.b/callback/app3", cred1, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(byPath, req), c.ShouldEqual, http.StatusNotFound)
+			c.So(errors.Is(rejected[4], ErrCallbackNoRoute), c.ShouldBeTrue)
+			c.So(h1.calls, c.ShouldEqual, 0)
+		})
+
+		c.Convey("按接收方路由时使用各应用的请求体上限", func() {
+			e := NewRxEndpoint(RxEndpointRouteByReceiver)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", "1000002"),
+				Credentials: []CallbackCredential{cred1},
+				Handler:     h1,
+				Options:     []HTTPHandlerOption{WithMaxBodySize(2 * DefaultMaxCallbackBodySize)},
+			}), c.ShouldBeNil)
+			c.So(e.RegisterApp(CallbackApp{
+				Key:         RxEndpointReceiverKey("ww6a112864f8022910", "1000003"),
+				Credentials: []CallbackCredential{cred2},
+				Handler:     h2,
+			}), c.ShouldBeNil)
+
+			// 在回调包末尾填充，使其超出默认上限
+			padded := func(req *http.Request) *http.Request {
+				var buf bytes.Buffer
+				_, _ = buf.ReadFrom(req.Body)
+				body := bytes.TrimSuffix(buf.Bytes(), []byte("</xml>"))
+				body = append(body, "<Pad>"+strings.Repeat("x", DefaultMaxCallbackBodySize)+"</Pad></xml>"...)
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				return req
+			}
+
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred1, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, padded(req)), c.ShouldEqual, http.StatusOK)
+			c.So(h1.calls, c.ShouldEqual, 1)
+
+			req, err = makeTestCallbackRequest("http://a.b/callback", cred2, "ww6a112864f8022910", "1000003", textBody)
+			c.So(err, c.ShouldBeNil)
+			c.So(do(e, padded(req)), c.ShouldEqual, http.StatusRequestEntityTooLarge)
+			c.So(h2.calls, c.ShouldEqual, 0)
+		})
+	})
+}
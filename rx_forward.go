@@ -0,0 +1,325 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ForwardTimestampHeader 转发请求中携带签名时间戳（Unix 秒）的 HTTP 头
+const ForwardTimestampHeader = "X-Workwx-Timestamp"
+
+// ForwardSignatureHeader 转发请求中携带签名的 HTTP 头
+//
+// 签名为以共享密钥对 "<时间戳>.<请求体>" 计算的 HMAC-SHA256，十六进制小写编码。
+const ForwardSignatureHeader = "X-Workwx-Signature"
+
+// DefaultForwardMaxRetries 转发失败时默认的最大重试次数
+const DefaultForwardMaxRetries = 3
+
+// DefaultForwardRetryBackoff 转发失败后默认的首次重试等待时间，此后每次翻倍
+const DefaultForwardRetryBackoff = 200 * time.Millisecond
+
+// DefaultForwardTotalTimeout 转发一条消息默认的总时限，含所有目标与重试
+//
+// 略短于企业微信等待回调响应的 5 秒，以免超时后企业微信重推、整条消息被再转发一遍。
+const DefaultForwardTotalTimeout = 4 * time.Second
+
+// DefaultForwardTimeout HTTPForwardSink 默认的单次请求超时时间
+//
+// 与企业微信等待回调响应的时间相同，下游迟迟不响应时不必再等下去。
+const DefaultForwardTimeout = 5 * time.Second
+
+// defaultForwardHTTPClient HTTPForwardSink 未指定 Client 时使用的客户端
+var defaultForwardHTTPClient = &http.Client{Timeout: DefaultForwardTimeout}
+
+// RxMessageFilter 按消息类型、事件类型与变更类型筛选消息
+//
+// 各字段为空表示不限；非空时消息的相应字段须为其中之一。
+type RxMessageFilter struct {
+	// MsgTypes 允许的消息类型
+	MsgTypes []MessageType
+	// Events 允许的事件类型
+	Events []EventType
+	// ChangeTypes 允许的变更类型
+	ChangeTypes []ChangeType
+}
+
+// Match 消息是否满足筛选条件
+func (f RxMessageFilter) Match(msg *RxMessage) bool {
+	if len(f.MsgTypes) > 0 && !slices.Contains(f.MsgTypes, msg.MsgType) {
+		return false
+	}
+	if len(f.Events) > 0 && !slices.Contains(f.Events, msg.Event) {
+		return false
+	}
+	if len(f.ChangeTypes) > 0 && !slices.Contains(f.ChangeTypes, msg.ChangeType) {
+		return false
+	}
+
+	return true
+}
+
+// RxForwardSink 回调消息的转发目的地，如下游 HTTP 服务或消息队列
+type RxForwardSink interface {
+	// Forward 投递一条消息，payload 为消息的 JSON 序列化结果
+	//
+	// 返回的错误如果实现了 Retryable() bool 且返回 false，则不再重试。
+	Forward(ctx context.Context, msg *RxMessage, payload []byte) error
+}
+
+// RxForwardTarget 一个转发目标
+type RxForwardTarget struct {
+	// Name 目标名称，用于错误信息
+	Name string
+	// Filter 只转发满足条件的消息
+	Filter RxMessageFilter
+	// Sink 转发目的地
+	Sink RxForwardSink
+}
+
+// RxForwardConfig 转发的配置
+type RxForwardConfig struct {
+	// MaxRetries 每个目标投递失败时的最大重试次数，默认为 DefaultForwardMaxRetries，为负则不重试
+	MaxRetries int
+	// RetryBackoff 首次重试前的等待时间，此后每次翻倍，默认为 DefaultForwardRetryBackoff
+	RetryBackoff time.Duration
+	// TotalTimeout 转发一条消息的总时限，含所有目标与重试，默认为 DefaultForwardTotalTimeout
+	//
+	// 到时仍未成功的目标视为失败，剩余的重试不再进行。开启 WithAsync 后回调已先行
+	// 响应，不受企业微信 5 秒的限制，可以按需调大。
+	TotalTimeout time.Duration
+	// OnError 某个目标最终投递失败时的回调，可以为 nil
+	OnError func(target string, msg *RxMessage, err error)
+}
+
+// RxForwarder 将回调消息以 JSON 形式转发给多个下游的 RxMessageHandler
+//
+// 企业微信每个应用只能配置一个回调 URL；RxForwarder 可以让 SDK 的回调端点充当
+// 事件网关，把消息按需分发给多个内部服务。消息并发投递给所有匹配的目标，
+// 全部投递（含重试）限时 RxForwardConfig.TotalTimeout 完成，以便在企业微信
+// 超时之前响应。任一目标最终失败时返回错误，企业微信随后会重试整条消息，
+// 已经成功的目标也会再收到一次，因此下游需要能处理重复投递（可以配合
+// WithDedup 及 RxMessage.DedupKey 去重）。
+type RxForwarder struct {
+	cfg     RxForwardConfig
+	targets []RxForwardTarget
+}
+
+var _ RxMessageHandler = (*RxForwarder)(nil)
+
+// NewRxForwarder 构造一个转发给给定目标的 RxForwarder
+func NewRxForwarder(cfg RxForwardConfig, targets ...RxForwardTarget) *RxForwarder {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultForwardMaxRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultForwardRetryBackoff
+	}
+	if cfg.TotalTimeout <= 0 {
+		cfg.TotalTimeout = DefaultForwardTotalTimeout
+	}
+
+	return &RxForwarder{
+		cfg:     cfg,
+		targets: targets,
+	}
+}
+
+// OnIncomingMessage 将消息转发给所有匹配的目标
+func (f *RxForwarder) OnIncomingMessage(msg *RxMessage) error {
+	var matched []RxForwardTarget
+	for _, t := range f.targets {
+		if t.Filter.Match(msg) {
+			matched = append(matched, t)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(msg.Context(), f.cfg.TotalTimeout)
+	defer cancel()
+
+	errs := make([]error, len(matched))
+	var wg sync.WaitGroup
+	for i, t := range matched {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := f.forwardWithRetry(ctx, msg, t.Sink, payload)
+			if err == nil {
+				return
+			}
+
+			errs[i] = fmt.Errorf("forward to %s: %w", t.Name, err)
+			if f.cfg.OnError != nil {
+				f.cfg.OnError(t.Name, msg, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (f *RxForwarder) forwardWithRetry(
+	ctx context.Context,
+	msg *RxMessage,
+	sink RxForwardSink,
+	payload []byte,
+) error {
+	backoff := f.cfg.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := sink.Forward(ctx, msg, payload)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= f.cfg.MaxRetries || !isRetryableForwardError(err) {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			// 等不到下一次重试了
+			return errors.Join(err, context.DeadlineExceeded)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryableForwardError(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+
+	return true
+}
+
+// ForwardStatusError 下游 HTTP 服务以非 2xx 状态码响应
+type ForwardStatusError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+}
+
+func (e *ForwardStatusError) Error() string {
+	return fmt.Sprintf("go-workwx: forward target responded with HTTP %d", e.StatusCode)
+}
+
+// Retryable 仅 5xx 与 429 值得重试
+func (e *ForwardStatusError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// HTTPForwardSink 以 POST 请求将消息转发给下游 HTTP 服务
+//
+// 请求体为 RxMessage 的 JSON 序列化结果；Secret 非空时会带上
+// ForwardTimestampHeader 与 ForwardSignatureHeader，下游可用
+// VerifyForwardSignature 校验。
+type HTTPForwardSink struct {
+	// URL 下游服务地址
+	URL string
+	// Secret 签名用的共享密钥，为空则不签名
+	Secret []byte
+	// Client 发送请求的 HTTP 客户端，为 nil 则使用超时时间为 DefaultForwardTimeout 的客户端
+	//
+	// 自行指定时应设置 Timeout，否则下游不响应会一直阻塞回调处理。
+	Client *http.Client
+
+	timeSource func() time.Time
+}
+
+var _ RxForwardSink = (*HTTPForwardSink)(nil)
+
+// NewHTTPForwardSink 构造一个转发给给定地址的 HTTPForwardSink
+func NewHTTPForwardSink(url string, secret []byte) *HTTPForwardSink {
+	return &HTTPForwardSink{
+		URL:        url,
+		Secret:     secret,
+		Client:     nil,
+		timeSource: time.Now,
+	}
+}
+
+// Forward 以 POST 请求投递消息
+func (s *HTTPForwardSink) Forward(ctx context.Context, _ *RxMessage, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(s.Secret) > 0 {
+		now := time.Now
+		if s.timeSource != nil {
+			now = s.timeSource
+		}
+		ts := strconv.FormatInt(now().Unix(), 10)
+		req.Header.Set(ForwardTimestampHeader, ts)
+		req.Header.Set(ForwardSignatureHeader, signForwardPayload(s.Secret, ts, payload))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = defaultForwardHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &ForwardStatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+func signForwardPayload(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyForwardSignature 校验 HTTPForwardSink 转发请求的签名
+//
+// timestamp 与 signature 分别取自 ForwardTimestampHeader 与 ForwardSignatureHeader；
+// 时间戳是否过期由调用方自行判断。
+func VerifyForwardSignature(secret []byte, timestamp string, payload []byte, signature string) bool {
+	expected := signForwardPayload(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,204 @@
+package workwx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+type recordingForwardSink struct {
+	mu       sync.Mutex
+	payloads [][]byte
+	errs     []error
+}
+
+func (s *recordingForwardSink) Forward(_ context.Context, _ *RxMessage, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.payloads = append(s.payloads, payload)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	return nil
+}
+
+// hangingForwardSink 直到 ctx 结束才返回
+type hangingForwardSink struct{}
+
+func (hangingForwardSink) Forward(ctx context.Context, _ *RxMessage, _ []byte) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRxForwarder(t *testing.T) {
+	c.Convey("回调转发", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+		eventBody := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_contact]]></Event><ChangeType><![CDATA[del_external_contact]]></ChangeType><UserID><![CDATA[zhangsan]]></UserID><ExternalUserID><![CDATA[woAJ2GCAAAXtWyujaWJHDDGi0mAAAA]]></ExternalUserID></xml>")
+		textMsg, err := fromEnvelope(textBody)
+		c.So(err, c.ShouldBeNil)
+		eventMsg, err := fromEnvelope(eventBody)
+		c.So(err, c.ShouldBeNil)
+
+		c.Convey("按条件分发给各目标", func() {
+			all := &recordingForwardSink{}
+			contacts := &recordingForwardSink{}
+			f := NewRxForwarder(
+				RxForwardConfig{},
+				RxForwardTarget{Name: "all", Sink: all},
+				RxForwardTarget{
+					Name: "contacts",
+					Filter: RxMessageFilter{
+						Events:      []EventType{EventTypeChangeExternalContact},
+						ChangeTypes: []ChangeType{ChangeTypeDelExternalContact},
+					},
+					Sink: contacts,
+				},
+			)
+
+			c.So(f.OnIncomingMessage(textMsg), c.ShouldBeNil)
+			c.So(f.OnIncomingMessage(eventMsg), c.ShouldBeNil)
+			c.So(all.payloads, c.ShouldHaveLength, 2)
+			c.So(contacts.payloads, c.ShouldHaveLength, 1)
+
+			var restored RxMessage
+			c.So(json.Unmarshal(contacts.payloads[0], &restored), c.ShouldBeNil)
+			c.So(restored.String(), c.ShouldEqual, eventMsg.String())
+		})
+
+		c.Convey("失败后重试", func() {
+			sink := &recordingForwardSink{
+				errs: []error{&ForwardStatusError{StatusCode: http.StatusBadGateway}},
+			}
+			f := NewRxForwarder(
+				RxForwardConfig{RetryBackoff: time.Millisecond},
+				RxForwardTarget{Name: "flaky", Sink: sink},
+			)
+
+			c.So(f.OnIncomingMessage(textMsg), c.ShouldBeNil)
+			c.So(sink.payloads, c.ShouldHaveLength, 2)
+		})
+
+		c.Convey("不可重试的错误立即失败", func() {
+			sink := &recordingForwardSink{
+				errs: []error{&ForwardStatusError{StatusCode: http.StatusBadRequest}},
+			}
+			var failedTarget string
+			f := NewRxForwarder(
+				RxForwardConfig{
+					RetryBackoff: time.Millisecond,
+					OnError: func(target string, _ *RxMessage, _ error) {
+						failedTarget = target
+					},
+				},
+				RxForwardTarget{Name: "strict", Sink: sink},
+			)
+
+			err := f.OnIncomingMessage(textMsg)
+			var statusErr *ForwardStatusError
+			c.So(errors.As(err, &statusErr), c.ShouldBeTrue)
+			c.So(statusErr.StatusCode, c.ShouldEqual, http.StatusBadRequest)
+			c.So(sink.payloads, c.ShouldHaveLength, 1)
+			c.So(failedTarget, c.ShouldEqual, "strict")
+		})
+
+		c.Convey("默认总时限短于企业微信的 5 秒", func() {
+			f := NewRxForwarder(RxForwardConfig{})
+			c.So(f.cfg.TotalTimeout, c.ShouldEqual, DefaultForwardTotalTimeout)
+			c.So(f.cfg.TotalTimeout, c.ShouldBeLessThan, 5*time.Second)
+		})
+
+		c.Convey("下游不响应时在总时限内返回", func() {
+			ok := &recordingForwardSink{}
+			f := NewRxForwarder(
+				RxForwardConfig{TotalTimeout: 50 * time.Millisecond},
+				RxForwardTarget{Name: "hanging", Sink: hangingForwardSink{}},
+				RxForwardTarget{Name: "ok", Sink: ok},
+			)
+
+			start := time.Now()
+			err := f.OnIncomingMessage(textMsg)
+			c.So(time.Since(start), c.ShouldBeLessThan, time.Second)
+			c.So(errors.Is(err, context.DeadlineExceeded), c.ShouldBeTrue)
+			c.So(ok.payloads, c.ShouldHaveLength, 1)
+		})
+
+		c.Convey("临近总时限时不再重试", func() {
+			sink := &recordingForwardSink{
+				errs: []error{
+					&ForwardStatusError{StatusCode: http.StatusBadGateway},
+					&ForwardStatusError{StatusCode: http.StatusBadGateway},
+				},
+			}
+			f := NewRxForwarder(
+				RxForwardConfig{RetryBackoff: time.Second, TotalTimeout: 50 * time.Millisecond},
+				RxForwardTarget{Name: "flaky", Sink: sink},
+			)
+
+			start := time.Now()
+			err := f.OnIncomingMessage(textMsg)
+			c.So(time.Since(start), c.ShouldBeLessThan, 500*time.Millisecond)
+			var statusErr *ForwardStatusError
+			c.So(errors.As(err, &statusErr), c.ShouldBeTrue)
+			c.So(sink.payloads, c.ShouldHaveLength, 1)
+		})
+
+		c.Convey("HTTP 转发带签名", func() {
+			secret := []byte("s3cr3t")
+			var gotBody []byte
+			var verified bool
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				gotBody, _ = io.ReadAll(r.Body)
+				verified = VerifyForwardSignature(
+					secret,
+					r.Header.Get(ForwardTimestampHeader),
+					gotBody,
+					r.Header.Get(ForwardSignatureHeader),
+				)
+				rw.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			f := NewRxForwarder(
+				RxForwardConfig{},
+				RxForwardTarget{Name: "http", Sink: NewHTTPForwardSink(srv.URL, secret)},
+			)
+
+			c.So(f.OnIncomingMessage(textMsg), c.ShouldBeNil)
+			c.So(verified, c.ShouldBeTrue)
+			c.So(VerifyForwardSignature([]byte("wrong"), "0", gotBody, "deadbeef"), c.ShouldBeFalse)
+
+			var restored RxMessage
+			c.So(json.Unmarshal(gotBody, &restored), c.ShouldBeNil)
+			c.So(restored.MsgID, c.ShouldEqual, 2018405441)
+		})
+
+		c.Convey("HTTP 转发默认带超时", func() {
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				<-release
+			}))
+			defer srv.Close()
+			defer close(release)
+
+			orig := defaultForwardHTTPClient
+			defaultForwardHTTPClient = &http.Client{Timeout: 10 * time.Millisecond}
+			defer func() { defaultForwardHTTPClient = orig }()
+
+			err := NewHTTPForwardSink(srv.URL, nil).Forward(context.Background(), textMsg, []byte("{}"))
+			var netErr interface{ Timeout() bool }
+			c.So(errors.As(err, &netErr), c.ShouldBeTrue)
+			c.So(netErr.Timeout(), c.ShouldBeTrue)
+		})
+	})
+}
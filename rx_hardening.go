@@ -0,0 +1,98 @@
+package workwx
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/httpapi"
+)
+
+// DefaultMaxCallbackBodySize 回调请求体默认的大小上限
+const DefaultMaxCallbackBodySize = httpapi.DefaultMaxBodySize
+
+// ErrCallbackBodyTooLarge 回调请求体超出大小上限
+var ErrCallbackBodyTooLarge = httpapi.ErrBodyTooLarge
+
+// ErrCallbackSourceNotAllowed 回调请求来源不在允许的范围内
+var ErrCallbackSourceNotAllowed = httpapi.ErrSourceNotAllowed
+
+// ErrCallbackMalformed 回调请求不是合法的回调包或缺少参数
+var ErrCallbackMalformed = envelope.ErrMalformedEnvelope
+
+// ErrCallbackInvalidSignature 回调请求签名校验失败，多半是 Token 不对或请求被篡改
+var ErrCallbackInvalidSignature = envelope.ErrInvalidSignature
+
+// ErrCallbackDecryptFailed 回调请求签名正确但解密失败，多半是 EncodingAESKey 不对
+var ErrCallbackDecryptFailed = envelope.ErrDecryptFailed
+
+// ErrCallbackReceiveIDMismatch 回调包解密所得的 ReceiveID 与期望的 CorpID 不符
+var ErrCallbackReceiveIDMismatch = httpapi.ErrReceiveIDMismatch
+
+// AllowCallbackSourceIPs 返回只接受来自给定 IP 段的请求的过滤函数，可用于 WithSourceFilter
+//
+// 按请求的 RemoteAddr 判断；部署在反向代理之后时，需要自行按代理设置的请求头判断。
+func AllowCallbackSourceIPs(ranges []netip.Prefix) func(r *http.Request) bool {
+	return httpapi.RemoteAddrIn(ranges)
+}
+
+// ParseCallbackIPRanges 解析企业微信公布的回调 IP 段
+//
+// 支持单个 IP、CIDR 以及企业微信所用的 "101.226.103.*" 形式的通配写法。
+func ParseCallbackIPRanges(list []string) ([]netip.Prefix, error) {
+	result := make([]netip.Prefix, 0, len(list))
+	for _, item := range list {
+		p, err := parseCallbackIPRange(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func parseCallbackIPRange(x string) (netip.Prefix, error) {
+	if strings.Contains(x, "/") {
+		return netip.ParsePrefix(x)
+	}
+
+	if !strings.Contains(x, "*") {
+		addr, err := netip.ParseAddr(x)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	// 只支持 IPv4 末尾若干段为 * 的写法
+	parts := strings.Split(x, ".")
+	if len(parts) != 4 {
+		return netip.Prefix{}, fmt.Errorf("go-workwx: malformed callback IP range %q", x)
+	}
+	bits := 32
+	for i := 3; i >= 0 && parts[i] == "*"; i-- {
+		parts[i] = "0"
+		bits -= 8
+	}
+	addr, err := netip.ParseAddr(strings.Join(parts, "."))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("go-workwx: malformed callback IP range %q", x)
+	}
+
+	return netip.PrefixFrom(addr, bits), nil
+}
+
+// GetCallbackIP 获取企业微信回调的来源 IP 段
+//
+// 结果可交给 ParseCallbackIPRanges 解析。
+func (c *WorkwxApp) GetCallbackIP() ([]string, error) {
+	resp, err := c.execGetCallbackIP(reqGetCallbackIP{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.IPList, nil
+}
@@ -0,0 +1,150 @@
+package workwx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseCallbackIPRanges(t *testing.T) {
+	c.Convey("解析回调 IP 段", t, func() {
+		ranges, err := ParseCallbackIPRanges([]string{"101.226.103.*", "101.226.*.*", "1.2.3.4", "10.0.0.0/8"})
+		c.So(err, c.ShouldBeNil)
+		c.So(ranges, c.ShouldResemble, []netip.Prefix{
+			netip.MustParsePrefix("101.226.103.0/24"),
+			netip.MustParsePrefix("101.226.0.0/16"),
+			netip.MustParsePrefix("1.2.3.4/32"),
+			netip.MustParsePrefix("10.0.0.0/8"),
+		})
+
+		_, err = ParseCallbackIPRanges([]string{"101.*.103.1"})
+		c.So(err, c.ShouldNotBeNil)
+	})
+}
+
+func TestGetCallbackIP(t *testing.T) {
+	c.Convey("获取回调 IP 段", t, func() {
+		var gotPath string
+		app, closeFn := newFakeQyapiApp(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","ip_list":["101.226.103.*","101.226.62.*"]}`))
+		}))
+		defer closeFn()
+
+		list, err := app.GetCallbackIP()
+		c.So(err, c.ShouldBeNil)
+		c.So(gotPath, c.ShouldEqual, "/cgi-bin/getcallbackip")
+		c.So(list, c.ShouldResemble, []string{"101.226.103.*", "101.226.62.*"})
+	})
+}
+
+func TestCallbackHardening(t *testing.T) {
+	c.Convey("回调端点加固", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+
+		//nolint: gosec  // randomly generated for test purposes only
+		cred := CallbackCredential{
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+		inner := &countingRxMessageHandler{}
+
+		var rejected []error
+		hook := WithRejectHook(func(_ *http.Request, err error) {
+			rejected = append(rejected, err)
+		})
+
+		serve := func(req *http.Request, opts ...HTTPHandlerOption) int {
+			h, err := NewHTTPHandlerWithCredentials([]CallbackCredential{cred}, inner, append(opts, hook)...)
+			c.So(err, c.ShouldBeNil)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		makeReq := func(cred CallbackCredential, corpID string) *http.Request {
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred, corpID, "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			return req
+		}
+
+		c.Convey("请求体过大", func() {
+			code := serve(makeReq(cred, "ww6a112864f8022910"), WithMaxBodySize(16))
+			c.So(code, c.ShouldEqual, http.StatusRequestEntityTooLarge)
+			c.So(errors.Is(rejected[0], ErrCallbackBodyTooLarge), c.ShouldBeTrue)
+			c.So(inner.calls, c.ShouldEqual, 0)
+		})
+
+		c.Convey("来源 IP 过滤", func() {
+			ranges, err := ParseCallbackIPRanges([]string{"101.226.103.*"})
+			c.So(err, c.ShouldBeNil)
+			filter := WithSourceFilter(AllowCallbackSourceIPs(ranges))
+
+			req := makeReq(cred, "ww6a112864f8022910")
+			req.RemoteAddr = "1.2.3.4:5678"
+			c.So(serve(req, filter), c.ShouldEqual, http.StatusForbidden)
+			c.So(errors.Is(rejected[0], ErrCallbackSourceNotAllowed), c.ShouldBeTrue)
+
+			req = makeReq(cred, "ww6a112864f8022910")
+			req.RemoteAddr = "101.226.103.5:5678"
+			c.So(serve(req, filter), c.ShouldEqual, http.StatusOK)
+			c.So(inner.calls, c.ShouldEqual, 1)
+		})
+
+		c.Convey("ReceiveID 与 CorpID 不符", func() {
+			receiveID := WithExpectedReceiveID("ww6a112864f8022910")
+			c.So(serve(makeReq(cred, "wwsomeoneelse"), receiveID), c.ShouldEqual, http.StatusBadRequest)
+			c.So(errors.Is(rejected[0], ErrCallbackReceiveIDMismatch), c.ShouldBeTrue)
+
+			c.So(serve(makeReq(cred, "ww6a112864f8022910"), receiveID), c.ShouldEqual, http.StatusOK)
+			c.So(inner.calls, c.ShouldEqual, 1)
+		})
+
+		c.Convey("重放请求计入 handler 的计数", func() {
+			h, err := NewHTTPHandlerWithCredentials(
+				[]CallbackCredential{cred},
+				inner,
+				hook,
+				WithReplayProtection(ReplayProtection{NonceCache: NewMemoryDedupStore(16)}),
+			)
+			c.So(err, c.ShouldBeNil)
+
+			req := makeReq(cred, "ww6a112864f8022910")
+			body, err := io.ReadAll(req.Body)
+			c.So(err, c.ShouldBeNil)
+			for _, code := range []int{http.StatusOK, http.StatusBadRequest} {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				c.So(rec.Code, c.ShouldEqual, code)
+			}
+			c.So(errors.Is(rejected[0], ErrCallbackReplayed), c.ShouldBeTrue)
+			c.So(h.ReplayRejectedCount(), c.ShouldEqual, 1)
+		})
+
+		c.Convey("签名与解密失败", func() {
+			//nolint: gosec  // randomly generated for test purposes only
+			wrongToken := CallbackCredential{
+				Token:          "QDG6eK3bOvFpBMb2hsSPKBPHXbjoDrqD",
+				EncodingAESKey: cred.EncodingAESKey,
+			}
+			c.So(serve(makeReq(wrongToken, "ww6a112864f8022910")), c.ShouldEqual, http.StatusBadRequest)
+			c.So(errors.Is(rejected[0], ErrCallbackInvalidSignature), c.ShouldBeTrue)
+
+			//nolint: gosec  // randomly generated for test purposes only
+			wrongKey := CallbackCredential{
+				Token:          cred.Token,
+				EncodingAESKey: "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C",
+			}
+			c.So(serve(makeReq(wrongKey, "ww6a112864f8022910")), c.ShouldEqual, http.StatusBadRequest)
+			c.So(errors.Is(rejected[1], ErrCallbackDecryptFailed), c.ShouldBeTrue)
+		})
+	})
+}
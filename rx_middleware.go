@@ -0,0 +1,123 @@
+package workwx
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// RxPanicError RxMessageHandler 处理消息时发生了 panic
+type RxPanicError struct {
+	// Value recover 得到的值
+	Value any
+	// Stack 发生 panic 时的调用栈
+	Stack []byte
+}
+
+func (e *RxPanicError) Error() string {
+	return fmt.Sprintf("go-workwx: panic in callback handler: %v", e.Value)
+}
+
+// Unwrap 如果 panic 的值是 error 则返回之
+func (e *RxPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// dispatchRecovering 调用 DispatchRxMessage，并将 panic 转换为 *RxPanicError
+func dispatchRecovering(h RxMessageHandler, msg *RxMessage) (reply RxReply, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			reply = nil
+			err = &RxPanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return DispatchRxMessage(h, msg)
+}
+
+// RxRecovery 将 next 中的 panic 转换为 *RxPanicError 错误的中间件
+//
+// HTTPHandler 本身已经兜住了 panic；在 RxMux 的路由上使用本中间件，可以让
+// 外层的日志、指标等中间件看到 panic。
+func RxRecovery() RxMiddleware {
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+			return dispatchRecovering(next, msg)
+		})
+	}
+}
+
+// RxLogging 以结构化日志记录每条消息的类型、处理耗时与结果的中间件
+//
+// 处理成功记为 Info 级别，失败记为 Error 级别。logger 为 nil 则使用 slog.Default()。
+func RxLogging(logger *slog.Logger) RxMiddleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+			start := time.Now()
+			reply, err := DispatchRxMessage(next, msg)
+
+			attrs := []slog.Attr{
+				slog.String("msg_type", string(msg.MsgType)),
+				slog.String("event", string(msg.Event)),
+				slog.String("change_type", string(msg.ChangeType)),
+				slog.Int64("agent_id", msg.AgentID),
+				slog.String("from_user_id", msg.FromUserID),
+				slog.Duration("latency", time.Since(start)),
+				slog.Bool("replied", reply != nil),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(msg.Context(), slog.LevelError, "go-workwx: callback handler failed", attrs...)
+			} else {
+				logger.LogAttrs(msg.Context(), slog.LevelInfo, "go-workwx: callback handled", attrs...)
+			}
+
+			return reply, err
+		})
+	}
+}
+
+// RxMetrics 在每条消息处理完毕后调用 observe 的中间件，用于上报处理耗时、失败次数等指标
+//
+// observe 会同步调用，不应阻塞。
+func RxMetrics(observe func(msg *RxMessage, latency time.Duration, err error)) RxMiddleware {
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+			start := time.Now()
+			reply, err := DispatchRxMessage(next, msg)
+			observe(msg, time.Since(start), err)
+			return reply, err
+		})
+	}
+}
+
+// RxErrorAction 消息处理失败时对企业微信的响应方式
+type RxErrorAction int
+
+const (
+	// RxErrorRetry 以 500 响应，企业微信稍后会重试推送
+	RxErrorRetry RxErrorAction = iota + 1
+	// RxErrorDrop 以 200 响应，丢弃该消息
+	RxErrorDrop
+)
+
+// RxErrorPolicy 决定消息处理失败时如何响应
+//
+// 消息解析失败时 msg 为 nil。
+type RxErrorPolicy func(msg *RxMessage, err error) RxErrorAction
+
+// RxErrorPolicyAlways 总是以给定方式响应的 RxErrorPolicy
+func RxErrorPolicyAlways(action RxErrorAction) RxErrorPolicy {
+	return func(*RxMessage, error) RxErrorAction {
+		return action
+	}
+}
@@ -0,0 +1,118 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxMiddlewares(t *testing.T) {
+	c.Convey("回调处理中间件", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+
+		//nolint: gosec  // randomly generated for test purposes only
+		cred := CallbackCredential{
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+
+		serve := func(inner RxMessageHandler, opts ...HTTPHandlerOption) int {
+			h, err := NewHTTPHandlerWithCredentials([]CallbackCredential{cred}, inner, opts...)
+			c.So(err, c.ShouldBeNil)
+
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		panicking := RxMessageHandlerFunc(func(*RxMessage) (RxReply, error) {
+			panic("boom")
+		})
+
+		c.Convey("panic 被兜住并报告给错误回调", func() {
+			var hookErr error
+			code := serve(panicking, WithErrorHook(func(_ *RxMessage, err error) {
+				hookErr = err
+			}))
+			c.So(code, c.ShouldEqual, http.StatusInternalServerError)
+
+			var panicErr *RxPanicError
+			c.So(errors.As(hookErr, &panicErr), c.ShouldBeTrue)
+			c.So(panicErr.Value, c.ShouldEqual, "boom")
+			c.So(panicErr.Stack, c.ShouldNotBeEmpty)
+		})
+
+		c.Convey("按策略丢弃处理失败的消息", func() {
+			failing := &countingRxMessageHandler{err: errFakeHandlerFailure}
+			c.So(serve(failing), c.ShouldEqual, http.StatusInternalServerError)
+			c.So(serve(failing, WithErrorPolicy(RxErrorPolicyAlways(RxErrorDrop))), c.ShouldEqual, http.StatusOK)
+
+			policy := func(_ *RxMessage, err error) RxErrorAction {
+				var panicErr *RxPanicError
+				if errors.As(err, &panicErr) {
+					return RxErrorDrop
+				}
+				return RxErrorRetry
+			}
+			c.So(serve(panicking, WithErrorPolicy(policy)), c.ShouldEqual, http.StatusOK)
+			c.So(serve(failing, WithErrorPolicy(policy)), c.ShouldEqual, http.StatusInternalServerError)
+		})
+
+		c.Convey("异步模式下的处理失败只报告一次", func() {
+			var hookErrs, asyncErrs []error
+			h, err := NewHTTPHandlerWithCredentials(
+				[]CallbackCredential{cred},
+				panicking,
+				WithAsync(AsyncConfig{
+					Workers: 1,
+					OnError: func(_ *RxMessage, err error) { asyncErrs = append(asyncErrs, err) },
+				}),
+				WithErrorHook(func(_ *RxMessage, err error) { hookErrs = append(hookErrs, err) }),
+			)
+			c.So(err, c.ShouldBeNil)
+
+			req, err := makeTestCallbackRequest("http://a.b/callback", cred, "ww6a112864f8022910", "1000002", textBody)
+			c.So(err, c.ShouldBeNil)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			c.So(rec.Code, c.ShouldEqual, http.StatusOK)
+			c.So(h.Shutdown(context.Background()), c.ShouldBeNil)
+
+			c.So(hookErrs, c.ShouldHaveLength, 1)
+			c.So(hookErrs[0], c.ShouldHaveSameTypeAs, &RxPanicError{})
+			c.So(asyncErrs, c.ShouldBeEmpty)
+		})
+
+		c.Convey("日志与指标", func() {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			var observed []error
+			metrics := RxMetrics(func(_ *RxMessage, latency time.Duration, err error) {
+				c.So(latency, c.ShouldBeGreaterThanOrEqualTo, 0)
+				observed = append(observed, err)
+			})
+
+			code := serve(
+				panicking,
+				WithMiddlewares(RxLogging(logger), metrics, RxRecovery()),
+			)
+			c.So(code, c.ShouldEqual, http.StatusInternalServerError)
+			c.So(observed, c.ShouldHaveLength, 1)
+			c.So(observed[0], c.ShouldHaveSameTypeAs, &RxPanicError{})
+			c.So(buf.String(), c.ShouldContainSubstring, `"level":"ERROR"`)
+			c.So(buf.String(), c.ShouldContainSubstring, `"msg_type":"text"`)
+			c.So(buf.String(), c.ShouldContainSubstring, `"latency"`)
+		})
+	})
+}
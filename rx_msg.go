@@ -1,6 +1,7 @@
 package workwx
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"strings"
@@ -24,6 +25,15 @@ type RxMessage struct {
 	// ChangeType 变更类型 Event为change_external_contact存在
 	ChangeType ChangeType
 
+	// toUserName 接收方，即企业的 CorpID；被动回复时作为发送方
+	toUserName string
+	// raw 解密后的原始消息体
+	raw []byte
+	// ctx 处理本消息的上下文，同步处理时即回调 HTTP 请求的上下文
+	ctx context.Context
+	// credentialID 解开本消息所用回调凭据的标识
+	credentialID string
+
 	extras messageKind
 }
 
@@ -57,6 +67,9 @@ func fromEnvelope(body []byte) (*RxMessage, error) {
 			Event:      common.Event,
 			ChangeType: common.ChangeType,
 
+			toUserName: common.ToUserName,
+			raw:        body,
+
 			extras: extras,
 		}
 	}
@@ -64,6 +77,25 @@ func fromEnvelope(body []byte) (*RxMessage, error) {
 	return &obj, nil
 }
 
+// Context 返回处理本消息的上下文
+//
+// 同步处理时为回调 HTTP 请求的上下文；异步处理时不会随请求结束而被取消。
+// 始终不为 nil。
+func (m *RxMessage) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+
+	return context.Background()
+}
+
+// CredentialID 返回解开本消息所用回调凭据的 ID
+//
+// 以 NewHTTPHandler 构造的 handler 收到的消息总是返回空字符串。
+func (m *RxMessage) CredentialID() string {
+	return m.credentialID
+}
+
 func (m *RxMessage) String() string {
 	var sb strings.Builder
 
@@ -86,134 +118,384 @@ func (m *RxMessage) String() string {
 	return sb.String()
 }
 
+// NOTE: 以下各方法按 extras 的具体类型取出参数。各事件的参数接口多有重合（如
+// 各菜单事件都只有 GetEventKey，更新成员的接口包含新增成员的），只断言接口会把
+// 一种事件误认作另一种。
+
 // Text 如果消息为文本类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Text() (TextMessageExtras, bool) {
-	y, ok := m.extras.(TextMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxTextMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Image 如果消息为图片类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Image() (ImageMessageExtras, bool) {
-	y, ok := m.extras.(ImageMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxImageMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Voice 如果消息为语音类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Voice() (VoiceMessageExtras, bool) {
-	y, ok := m.extras.(VoiceMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxVoiceMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Video 如果消息为视频类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Video() (VideoMessageExtras, bool) {
-	y, ok := m.extras.(VideoMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxVideoMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Location 如果消息为位置类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Location() (LocationMessageExtras, bool) {
-	y, ok := m.extras.(LocationMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxLocationMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Link 如果消息为链接类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Link() (LinkMessageExtras, bool) {
-	y, ok := m.extras.(LinkMessageExtras)
-	return y, ok
+	y, ok := m.extras.(*rxLinkMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAddExternalContact 如果消息为添加企业客户事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventAddExternalContact() (EventAddExternalContact, bool) {
-	y, ok := m.extras.(EventAddExternalContact)
-	return y, ok
+	y, ok := m.extras.(*rxEventAddExternalContact)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventEditExternalContact 如果消息为编辑企业客户事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventEditExternalContact() (EventEditExternalContact, bool) {
-	y, ok := m.extras.(EventEditExternalContact)
-	return y, ok
+	y, ok := m.extras.(*rxEventEditExternalContact)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventDelExternalContact 如果消息为删除企业客户事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventDelExternalContact() (EventDelExternalContact, bool) {
-	y, ok := m.extras.(EventDelExternalContact)
-	return y, ok
+	y, ok := m.extras.(*rxEventDelExternalContact)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventDelFollowUser 如果消息为删除跟进成员事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventDelFollowUser() (EventDelFollowUser, bool) {
-	y, ok := m.extras.(EventDelFollowUser)
-	return y, ok
+	y, ok := m.extras.(*rxEventDelFollowUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAddHalfExternalContact 如果消息为外部联系人免验证添加成员事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventAddHalfExternalContact() (EventAddHalfExternalContact, bool) {
-	y, ok := m.extras.(EventAddHalfExternalContact)
-	return y, ok
+	y, ok := m.extras.(*rxEventAddHalfExternalContact)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventTransferFail 如果消息为客户接替失败事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventTransferFail() (EventTransferFail, bool) {
-	y, ok := m.extras.(EventTransferFail)
-	return y, ok
+	y, ok := m.extras.(*rxEventTransferFail)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventChangeExternalChat 如果消息为客户群变更事件，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventChangeExternalChat() (EventChangeExternalChat, bool) {
-	y, ok := m.extras.(EventChangeExternalChat)
-	return y, ok
+	y, ok := m.extras.(*rxEventChangeExternalChat)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeExternalTag 如果消息为企业客户标签变更事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeExternalTag() (EventChangeExternalTag, bool) {
+	y, ok := m.extras.(*rxEventChangeExternalTag)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventSysApprovalChange 如果消息为审批申请状态变化回调通知，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventSysApprovalChange() (EventSysApprovalChange, bool) {
-	y, ok := m.extras.(EventSysApprovalChange)
-	return y, ok
+	y, ok := m.extras.(*rxEventSysApprovalChange)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventChangeTypeUpdateUser 如果消息为更新成员事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventChangeTypeUpdateUser() (*rxEventChangeTypeUpdateUser, bool) {
+func (m *RxMessage) EventChangeTypeUpdateUser() (EventChangeTypeUpdateUser, bool) {
 	y, ok := m.extras.(*rxEventChangeTypeUpdateUser)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventChangeTypeCreateUser  如果消息为创建成员事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventChangeTypeCreateUser() (*rxEventChangeTypeCreateUser, bool) {
+func (m *RxMessage) EventChangeTypeCreateUser() (EventChangeTypeCreateUser, bool) {
 	y, ok := m.extras.(*rxEventChangeTypeCreateUser)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeDeleteUser 如果消息为删除成员事件通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeTypeDeleteUser() (EventChangeTypeDeleteUser, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeDeleteUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeCreateParty 如果消息为新增部门事件通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeTypeCreateParty() (EventChangeTypeCreateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeCreateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeUpdateParty 如果消息为更新部门事件通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeTypeUpdateParty() (EventChangeTypeUpdateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeUpdateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeDeleteParty 如果消息为删除部门事件通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeTypeDeleteParty() (EventChangeTypeDeleteParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeDeleteParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeUpdateTag 如果消息为标签成员变更事件通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeTypeUpdateTag() (EventChangeTypeUpdateTag, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeUpdateTag)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAppMenuClick  如果消息为应用菜单点击事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventAppMenuClick() (*rxEventAppMenuClick, bool) {
+func (m *RxMessage) EventAppMenuClick() (EventAppMenuClick, bool) {
 	y, ok := m.extras.(*rxEventAppMenuClick)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAppMenuView  如果消息为应用菜单链接点击事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventAppMenuView() (*rxEventAppMenuView, bool) {
+func (m *RxMessage) EventAppMenuView() (EventAppMenuView, bool) {
 	y, ok := m.extras.(*rxEventAppMenuView)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuScanCodePush 如果消息为扫码推事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuScanCodePush() (EventAppMenuScanCode, bool) {
+	y, ok := m.extras.(*rxEventAppMenuScanCodePush)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuScanCodeWaitMsg 如果消息为扫码推事件且弹出“消息接收中”提示框，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuScanCodeWaitMsg() (EventAppMenuScanCode, bool) {
+	y, ok := m.extras.(*rxEventAppMenuScanCodeWaitMsg)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuPicSysPhoto 如果消息为弹出系统拍照发图事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuPicSysPhoto() (EventAppMenuPics, bool) {
+	y, ok := m.extras.(*rxEventAppMenuPicSysPhoto)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuPicPhotoOrAlbum 如果消息为弹出拍照或者相册发图事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuPicPhotoOrAlbum() (EventAppMenuPics, bool) {
+	y, ok := m.extras.(*rxEventAppMenuPicPhotoOrAlbum)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuPicWeixin 如果消息为弹出微信相册发图器事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuPicWeixin() (EventAppMenuPics, bool) {
+	y, ok := m.extras.(*rxEventAppMenuPicWeixin)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuLocationSelect 如果消息为弹出地理位置选择器事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuLocationSelect() (EventAppMenuLocationSelect, bool) {
+	y, ok := m.extras.(*rxEventAppMenuLocationSelect)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventAppMenuViewMiniprogram 如果消息为点击菜单跳转小程序事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventAppMenuViewMiniprogram() (EventAppMenuViewMiniprogram, bool) {
+	y, ok := m.extras.(*rxEventAppMenuViewMiniprogram)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventTemplateCardEvent 如果消息为模板卡片事件推送，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventTemplateCardEvent() (EventTemplateCardEvent, bool) {
+	y, ok := m.extras.(*rxEventTemplateCardEvent)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventTemplateCardMenuEvent 如果消息为通用模板卡片右上角菜单事件推送，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventTemplateCardMenuEvent() (EventTemplateCardMenuEvent, bool) {
+	y, ok := m.extras.(*rxEventTemplateCardMenuEvent)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventTaskCardClick 如果消息为任务卡片事件推送，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventTaskCardClick() (EventTaskCardClick, bool) {
+	y, ok := m.extras.(*rxEventTaskCardClick)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventEnterAgent 如果消息为进入应用事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventEnterAgent() (EventEnterAgent, bool) {
+	y, ok := m.extras.(*rxEventEnterAgent)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventLocation 如果消息为上报地理位置事件，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventLocation() (EventLocation, bool) {
+	y, ok := m.extras.(*rxEventLocation)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventBatchJobResult 如果消息为异步任务完成通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventBatchJobResult() (EventBatchJobResult, bool) {
+	y, ok := m.extras.(*rxEventBatchJobResult)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventOpenApprovalChange 如果消息为自建应用审批状态通知，则拿出相应消息参数，否则返回 nil, false
+func (m *RxMessage) EventOpenApprovalChange() (EventOpenApprovalChange, bool) {
+	y, ok := m.extras.(*rxEventOpenApprovalChange)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAppSubscribe  如果消息为应用订阅事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventAppSubscribe() (*rxEventAppSubscribe, bool) {
+func (m *RxMessage) EventAppSubscribe() (EventAppSubscribe, bool) {
 	y, ok := m.extras.(*rxEventAppSubscribe)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventAppUnsubscribe  如果消息为应用订阅取消事件通知，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventAppUnsubscribe() (*rxEventAppUnsubscribe, bool) {
+func (m *RxMessage) EventAppUnsubscribe() (EventAppUnsubscribe, bool) {
 	y, ok := m.extras.(*rxEventAppUnsubscribe)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // EventKfMsgOrEvent  如果消息为客服接收消息和事件，则拿出相应消息参数，否则返回 nil, false
-func (m *RxMessage) EventKfMsgOrEvent() (*rxEventKfMsgOrEvent, bool) {
+func (m *RxMessage) EventKfMsgOrEvent() (EventKfMsgOrEvent, bool) {
 	y, ok := m.extras.(*rxEventKfMsgOrEvent)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
-// EventUnknown  未定义的event类型
-func (m *RxMessage) EventUnknown() (*rxEventUnknown, bool) {
+// EventUnknown 如果事件类型未定义，则拿出原始消息体，否则返回 nil, false
+func (m *RxMessage) EventUnknown() (EventUnknown, bool) {
 	y, ok := m.extras.(*rxEventUnknown)
-	return y, ok
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
@@ -5,21 +5,21 @@ package workwx
 // rxMessageCommon 接收消息的公共部分
 type rxMessageCommon struct {
 	// ToUserName 企业微信CorpID
-	ToUserName string `xml:"ToUserName"`
+	ToUserName string `json:"ToUserName" xml:"ToUserName"`
 	// FromUserName 成员UserID
-	FromUserName string `xml:"FromUserName"`
+	FromUserName string `json:"FromUserName" xml:"FromUserName"`
 	// CreateTime 消息创建时间（整型）
-	CreateTime int64 `xml:"CreateTime"`
+	CreateTime int64 `json:"CreateTime" xml:"CreateTime"`
 	// MsgType 消息类型
-	MsgType MessageType `xml:"MsgType"`
+	MsgType MessageType `json:"MsgType" xml:"MsgType"`
 	// MsgID 消息id，64位整型
-	MsgID int64 `xml:"MsgId"`
+	MsgID int64 `json:"MsgId" xml:"MsgId"`
 	// AgentID 企业应用的id，整型。可在应用的设置页面查看
-	AgentID int64 `xml:"AgentID"`
+	AgentID int64 `json:"AgentID" xml:"AgentID"`
 	// Event 事件类型 MsgType为event存在
-	Event EventType `xml:"Event"`
+	Event EventType `json:"Event" xml:"Event"`
 	// ChangeType 变更类型 Event为change_external_contact存在
-	ChangeType ChangeType `xml:"ChangeType"`
+	ChangeType ChangeType `json:"ChangeType" xml:"ChangeType"`
 }
 
 // MessageType 消息类型
@@ -61,6 +61,9 @@ const EventTypeSysApprovalChange EventType = "sys_approval_change"
 // EventTypeChangeContact 通讯录回调通知
 const EventTypeChangeContact EventType = "change_contact"
 
+// EventTypeChangeExternalTag 企业客户标签变更事件
+const EventTypeChangeExternalTag EventType = "change_external_tag"
+
 // EventTypeKfMsgOrEvent 客服回调通知
 const EventTypeKfMsgOrEvent EventType = "kf_msg_or_event"
 
@@ -85,12 +88,60 @@ const ChangeTypeDelFollowUser ChangeType = "del_follow_user"
 // ChangeTypeTransferFail 客户接替失败事件
 const ChangeTypeTransferFail ChangeType = "transfer_fail"
 
+// ChangeTypeCreate 客户群创建、企业客户标签创建事件
+const ChangeTypeCreate ChangeType = "create"
+
+// ChangeTypeUpdate 客户群变更、企业客户标签变更事件
+const ChangeTypeUpdate ChangeType = "update"
+
+// ChangeTypeDismiss 客户群解散事件
+const ChangeTypeDismiss ChangeType = "dismiss"
+
+// ChangeTypeDelete 企业客户标签删除事件
+const ChangeTypeDelete ChangeType = "delete"
+
+// ChangeTypeShuffle 企业客户标签重排事件
+const ChangeTypeShuffle ChangeType = "shuffle"
+
+// ExternalChatUpdateDetail 客户群变更详情
+type ExternalChatUpdateDetail string
+
+// ExternalChatUpdateDetailAddMember 成员入群
+const ExternalChatUpdateDetailAddMember ExternalChatUpdateDetail = "add_member"
+
+// ExternalChatUpdateDetailDelMember 成员退群
+const ExternalChatUpdateDetailDelMember ExternalChatUpdateDetail = "del_member"
+
+// ExternalChatUpdateDetailChangeOwner 群主变更
+const ExternalChatUpdateDetailChangeOwner ExternalChatUpdateDetail = "change_owner"
+
+// ExternalChatUpdateDetailChangeName 群名变更
+const ExternalChatUpdateDetailChangeName ExternalChatUpdateDetail = "change_name"
+
+// ExternalChatUpdateDetailChangeNotice 群公告变更
+const ExternalChatUpdateDetailChangeNotice ExternalChatUpdateDetail = "change_notice"
+
 // ChangeTypeCreateUser 新增成员事件
 const ChangeTypeCreateUser ChangeType = "create_user"
 
 // ChangeTypeUpdateUser 更新成员事件
 const ChangeTypeUpdateUser ChangeType = "update_user"
 
+// ChangeTypeDeleteUser 删除成员事件
+const ChangeTypeDeleteUser ChangeType = "delete_user"
+
+// ChangeTypeCreateParty 新增部门事件
+const ChangeTypeCreateParty ChangeType = "create_party"
+
+// ChangeTypeUpdateParty 更新部门事件
+const ChangeTypeUpdateParty ChangeType = "update_party"
+
+// ChangeTypeDeleteParty 删除部门事件
+const ChangeTypeDeleteParty ChangeType = "delete_party"
+
+// ChangeTypeUpdateTag 标签成员变更事件
+const ChangeTypeUpdateTag ChangeType = "update_tag"
+
 // EventTypeAppMenuClick 点击菜单
 const EventTypeAppMenuClick = "click"
 
@@ -115,6 +166,30 @@ const EventTypeAppMenuPicWeixin = "pic_weixin"
 // EventTypeAppMenuLocationSelect 弹出微信位置选择器
 const EventTypeAppMenuLocationSelect = "location_select"
 
+// EventTypeAppMenuViewMiniprogram 点击菜单跳转小程序
+const EventTypeAppMenuViewMiniprogram = "view_miniprogram"
+
+// EventTypeTemplateCardEvent 模板卡片事件推送
+const EventTypeTemplateCardEvent EventType = "template_card_event"
+
+// EventTypeTemplateCardMenuEvent 通用模板卡片右上角菜单事件推送
+const EventTypeTemplateCardMenuEvent EventType = "template_card_menu_event"
+
+// EventTypeTaskCardClick 任务卡片事件推送
+const EventTypeTaskCardClick EventType = "taskcard_click"
+
+// EventTypeEnterAgent 进入应用
+const EventTypeEnterAgent EventType = "enter_agent"
+
+// EventTypeLocation 上报地理位置
+const EventTypeLocation EventType = "LOCATION"
+
+// EventTypeBatchJobResult 异步任务完成通知
+const EventTypeBatchJobResult EventType = "batch_job_result"
+
+// EventTypeOpenApprovalChange 审批状态通知事件，仅自建应用审批
+const EventTypeOpenApprovalChange EventType = "open_approval_change"
+
 // EventTypeAppSubscribe 应用订阅
 const EventTypeAppSubscribe = "subscribe"
 
@@ -124,263 +199,561 @@ const EventTypeAppUnsubscribe = "unsubscribe"
 // rxTextMessageSpecifics 接收的文本消息，特有字段
 type rxTextMessageSpecifics struct {
 	// Content 文本消息内容
-	Content string `xml:"Content"`
+	Content string `json:"Content" xml:"Content"`
 }
 
 // rxImageMessageSpecifics 接收的图片消息，特有字段
 type rxImageMessageSpecifics struct {
 	// PicURL 图片链接
-	PicURL string `xml:"PicUrl"`
+	PicURL string `json:"PicUrl" xml:"PicUrl"`
 	// MediaID 图片媒体文件id，可以调用获取媒体文件接口拉取，仅三天内有效
-	MediaID string `xml:"MediaId"`
+	MediaID string `json:"MediaId" xml:"MediaId"`
 }
 
 // rxVoiceMessageSpecifics 接收的语音消息，特有字段
 type rxVoiceMessageSpecifics struct {
 	// MediaID 语音媒体文件id，可以调用获取媒体文件接口拉取数据，仅三天内有效
-	MediaID string `xml:"MediaId"`
+	MediaID string `json:"MediaId" xml:"MediaId"`
 	// Format 语音格式，如amr，speex等
-	Format string `xml:"Format"`
+	Format string `json:"Format" xml:"Format"`
 }
 
 // rxVideoMessageSpecifics 接收的视频消息，特有字段
 type rxVideoMessageSpecifics struct {
 	// MediaID 视频媒体文件id，可以调用获取媒体文件接口拉取数据，仅三天内有效
-	MediaID string `xml:"MediaId"`
+	MediaID string `json:"MediaId" xml:"MediaId"`
 	// ThumbMediaID 视频消息缩略图的媒体id，可以调用获取媒体文件接口拉取数据，仅三天内有效
-	ThumbMediaID string `xml:"ThumbMediaId"`
+	ThumbMediaID string `json:"ThumbMediaId" xml:"ThumbMediaId"`
 }
 
 // rxLocationMessageSpecifics 接收的位置消息，特有字段
 type rxLocationMessageSpecifics struct {
 	// Lat 地理位置纬度
-	Lat float64 `xml:"Location_X"`
+	Lat float64 `json:"Location_X" xml:"Location_X"`
 	// Lon 地理位置经度
-	Lon float64 `xml:"Location_Y"`
+	Lon float64 `json:"Location_Y" xml:"Location_Y"`
 	// Scale 地图缩放大小
-	Scale int `xml:"Scale"`
+	Scale int `json:"Scale" xml:"Scale"`
 	// Label 地理位置信息
-	Label string `xml:"Label"`
+	Label string `json:"Label" xml:"Label"`
 	// AppType app类型，在企业微信固定返回wxwork，在微信不返回该字段
-	AppType string `xml:"AppType"`
+	AppType string `json:"AppType" xml:"AppType"`
 }
 
 // rxLinkMessageSpecifics 接收的链接消息，特有字段
 type rxLinkMessageSpecifics struct {
 	// Title 标题
-	Title string `xml:"Title"`
+	Title string `json:"Title" xml:"Title"`
 	// Description 描述
-	Description string `xml:"Description"`
+	Description string `json:"Description" xml:"Description"`
 	// URL 链接跳转的url
-	URL string `xml:"Url"`
+	URL string `json:"Url" xml:"Url"`
 	// PicURL 封面缩略图的url
-	PicURL string `xml:"PicUrl"`
+	PicURL string `json:"PicUrl" xml:"PicUrl"`
 }
 
 // rxEventAddExternalContact 接收的事件消息，添加企业客户事件
 type rxEventAddExternalContact struct {
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 	// State 添加此用户的「联系我」方式配置的state参数，可用于识别添加此用户的渠道
-	State string `xml:"State"`
+	State string `json:"State" xml:"State"`
 	// WelcomeCode 欢迎语code，可用于发送欢迎语
-	WelcomeCode string `xml:"WelcomeCode"`
+	WelcomeCode string `json:"WelcomeCode" xml:"WelcomeCode"`
 }
 
 // rxEventEditExternalContact 接收的事件消息，编辑企业客户事件
 type rxEventEditExternalContact struct {
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 	// State 添加此用户的「联系我」方式配置的state参数，可用于识别添加此用户的渠道
-	State string `xml:"State"`
+	State string `json:"State" xml:"State"`
 }
 
 // rxEventAddHalfExternalContact 接收的事件消息，外部联系人免验证添加成员事件
 type rxEventAddHalfExternalContact struct {
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 	// State 添加此用户的「联系我」方式配置的state参数，可用于识别添加此用户的渠道
-	State string `xml:"State"`
+	State string `json:"State" xml:"State"`
 	// WelcomeCode 欢迎语code，可用于发送欢迎语
-	WelcomeCode string `xml:"WelcomeCode"`
+	WelcomeCode string `json:"WelcomeCode" xml:"WelcomeCode"`
 }
 
 // rxEventDelExternalContact 接收的事件消息，删除企业客户事件
 type rxEventDelExternalContact struct {
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 }
 
 // rxEventDelFollowUser 接收的事件消息，删除跟进成员事件
 type rxEventDelFollowUser struct {
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 }
 
 // rxEventTransferFail 接收的事件消息，客户接替失败事件
 type rxEventTransferFail struct {
 	// FailReason 接替失败的原因, customer_refused-客户拒绝， customer_limit_exceed-接替成员的客户数达到上限
-	FailReason string `xml:"FailReason"`
+	FailReason string `json:"FailReason" xml:"FailReason"`
 	// UserID 企业服务人员的UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// ExternalUserID 外部联系人的userid，注意不是企业成员的帐号
-	ExternalUserID string `xml:"ExternalUserID"`
+	ExternalUserID string `json:"ExternalUserID" xml:"ExternalUserID"`
 }
 
 // rxEventChangeExternalChat 接收的事件消息，客户群变更事件
 type rxEventChangeExternalChat struct {
 	// ToUserName 企业微信CorpID
-	ToUserName string `xml:"ToUserName"`
+	ToUserName string `json:"ToUserName" xml:"ToUserName"`
 	// FromUserName 此事件该值固定为sys，表示该消息由系统生成
-	FromUserName string `xml:"FromUserName"`
+	FromUserName string `json:"FromUserName" xml:"FromUserName"`
 	// FailReason 接替失败的原因, customer_refused-客户拒绝， customer_limit_exceed-接替成员的客户数达到上限
-	FailReason string `xml:"FailReason"`
+	FailReason string `json:"FailReason" xml:"FailReason"`
 	// ChatID 群ID
-	ChatID string `xml:"ChatId"`
+	ChatID string `json:"ChatId" xml:"ChatId"`
+	// UpdateDetail 变更详情，ChangeType为update时存在
+	UpdateDetail ExternalChatUpdateDetail `json:"UpdateDetail" xml:"UpdateDetail"`
+	// JoinScene 当是成员入群时有值。表示成员的入群方式：0-由成员邀请入群（包括直接邀请入群和通过邀请链接入群）；3-通过扫描群二维码入群
+	JoinScene int `json:"JoinScene" xml:"JoinScene"`
+	// QuitScene 当是成员退群时有值。表示成员的退群方式：0-自己退群；1-群主/群管理员移出
+	QuitScene int `json:"QuitScene" xml:"QuitScene"`
+	// MemChangeCnt 当是成员入群或退群时有值。表示成员变更数量
+	MemChangeCnt int `json:"MemChangeCnt" xml:"MemChangeCnt"`
+	// MemChangeList 当是成员入群或退群时有值。变更的成员列表
+	MemChangeList []string `json:"MemChangeList" xml:"MemChangeList>Item"`
+	// LastMemVer 当是成员入群或退群时有值。变更前的群成员版本号
+	LastMemVer string `json:"LastMemVer" xml:"LastMemVer"`
+	// CurMemVer 当是成员入群或退群时有值。变更后的群成员版本号
+	CurMemVer string `json:"CurMemVer" xml:"CurMemVer"`
+}
+
+// rxEventChangeExternalTag 接收的事件消息，企业客户标签变更事件
+type rxEventChangeExternalTag struct {
+	// ID 标签或标签组的ID，ChangeType为shuffle时为标签组ID，为空表示对所有标签组重排
+	ID string `json:"Id" xml:"Id"`
+	// TagType 创建、变更、删除时为标签或标签组，tag-标签，tag_group-标签组
+	TagType string `json:"TagType" xml:"TagType"`
+	// StrategyID 标签或标签组所属的规则组id，只有规则组标签会有此字段
+	StrategyID int64 `json:"StrategyId" xml:"StrategyId"`
 }
 
 // rxEventSysApprovalChange 接收的事件消息，审批申请状态变化回调通知
 type rxEventSysApprovalChange struct {
 	// ApprovalInfo 审批信息、
-	ApprovalInfo OAApprovalInfo `xml:"ApprovalInfo"`
+	ApprovalInfo OAApprovalInfo `json:"ApprovalInfo" xml:"ApprovalInfo"`
 }
 
 // rxEventChangeTypeCreateUser 接受的事件消息，新增成员事件
 type rxEventChangeTypeCreateUser struct {
 	// UserID 成员UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// Name 成员名称
-	Name string `xml:"Name"`
+	Name string `json:"Name" xml:"Name"`
 	// Department 成员部门列表，仅返回该应用有查看权限的部门id
-	Department string `xml:"Department"`
+	Department string `json:"Department" xml:"Department"`
 	// IsLeaderInDept 表示所在部门是否为上级，0-否，1-是，顺序与Department字段的部门逐一对应
-	IsLeaderInDept string `xml:"IsLeaderInDept"`
+	IsLeaderInDept string `json:"IsLeaderInDept" xml:"IsLeaderInDept"`
 	// Mobile 手机号
-	Mobile string `xml:"Mobile"`
+	Mobile string `json:"Mobile" xml:"Mobile"`
 	// Position 职位信息。长度为0~64个字节
-	Position string `xml:"Position"`
+	Position string `json:"Position" xml:"Position"`
 	// Gender 性别，1表示男性，2表示女性
-	Gender int `xml:"Gender"`
+	Gender int `json:"Gender" xml:"Gender"`
 	// Email 邮箱
-	Email string `xml:"Email"`
+	Email string `json:"Email" xml:"Email"`
 	// Status 激活状态：1=已激活 2=已禁用 4=未激活 已激活代表已激活企业微信或已关注微工作台（原企业号）5=成员退出
-	Status int `xml:"Status"`
+	Status int `json:"Status" xml:"Status"`
 	// Avatar 头像url。注：如果要获取小图将url最后的”/0”改成”/100”即可。
-	Avatar string `xml:"Avatar"`
+	Avatar string `json:"Avatar" xml:"Avatar"`
 	// Alias 成员别名
-	Alias string `xml:"Alias"`
+	Alias string `json:"Alias" xml:"Alias"`
 	// Telephone 座机
-	Telephone string `xml:"Telephone"`
+	Telephone string `json:"Telephone" xml:"Telephone"`
 	// Address 地址
-	Address string `xml:"Address"`
+	Address string `json:"Address" xml:"Address"`
 	// ExtAttr 扩展属性
-	ExtAttr string `xml:"ExtAttr"`
+	ExtAttr string `json:"ExtAttr" xml:"ExtAttr"`
 	// Type 扩展属性类型: 0-本文 1-网页
-	Type string `xml:"Type"`
+	Type string `json:"Type" xml:"Type"`
 	// Text 文本属性类型，扩展属性类型为0时填写
-	Text string `xml:"Text"`
+	Text string `json:"Text" xml:"Text"`
 	// Value 文本属性内容
-	Value string `xml:"Value"`
+	Value string `json:"Value" xml:"Value"`
 	// Web 网页类型属性，扩展属性类型为1时填写
-	Web string `xml:"Web"`
+	Web string `json:"Web" xml:"Web"`
 	// Title 网页的展示标题
-	Title string `xml:"Title"`
+	Title string `json:"Title" xml:"Title"`
 	// Url 网页的url
-	Url string `xml:"Url"`
+	Url string `json:"Url" xml:"Url"`
 }
 
 // rxEventChangeTypeUpdateUser 接受的事件消息，更新成员事件
 type rxEventChangeTypeUpdateUser struct {
 	// UserID 成员UserID
-	UserID string `xml:"UserID"`
+	UserID string `json:"UserID" xml:"UserID"`
 	// NewUserID 新的UserID，变更时推送（userid由系统生成时可更改一次）
-	NewUserID string `xml:"NewUserID"`
+	NewUserID string `json:"NewUserID" xml:"NewUserID"`
 	// Name 成员名称
-	Name string `xml:"Name"`
+	Name string `json:"Name" xml:"Name"`
 	// Department 成员部门列表，仅返回该应用有查看权限的部门id
-	Department string `xml:"Department"`
+	Department string `json:"Department" xml:"Department"`
 	// IsLeaderInDept 表示所在部门是否为上级，0-否，1-是，顺序与Department字段的部门逐一对应
-	IsLeaderInDept string `xml:"IsLeaderInDept"`
+	IsLeaderInDept string `json:"IsLeaderInDept" xml:"IsLeaderInDept"`
 	// Mobile 手机号
-	Mobile string `xml:"Mobile"`
+	Mobile string `json:"Mobile" xml:"Mobile"`
 	// Position 职位信息。长度为0~64个字节
-	Position string `xml:"Position"`
+	Position string `json:"Position" xml:"Position"`
 	// Gender 性别，1表示男性，2表示女性
-	Gender int `xml:"Gender"`
+	Gender int `json:"Gender" xml:"Gender"`
 	// Email 邮箱
-	Email string `xml:"Email"`
+	Email string `json:"Email" xml:"Email"`
 	// Status 激活状态：1=已激活 2=已禁用 4=未激活 已激活代表已激活企业微信或已关注微工作台（原企业号）5=成员退出
-	Status int `xml:"Status"`
+	Status int `json:"Status" xml:"Status"`
 	// Avatar 头像url。注：如果要获取小图将url最后的”/0”改成”/100”即可。
-	Avatar string `xml:"Avatar"`
+	Avatar string `json:"Avatar" xml:"Avatar"`
 	// Alias 成员别名
-	Alias string `xml:"Alias"`
+	Alias string `json:"Alias" xml:"Alias"`
 	// Telephone 座机
-	Telephone string `xml:"Telephone"`
+	Telephone string `json:"Telephone" xml:"Telephone"`
 	// Address 地址
-	Address string `xml:"Address"`
+	Address string `json:"Address" xml:"Address"`
 	// ExtAttr 扩展属性
-	ExtAttr string `xml:"ExtAttr"`
+	ExtAttr string `json:"ExtAttr" xml:"ExtAttr"`
 	// Type 扩展属性类型: 0-本文 1-网页
-	Type string `xml:"Type"`
+	Type string `json:"Type" xml:"Type"`
 	// Text 文本属性类型，扩展属性类型为0时填写
-	Text string `xml:"Text"`
+	Text string `json:"Text" xml:"Text"`
 	// Value 文本属性内容
-	Value string `xml:"Value"`
+	Value string `json:"Value" xml:"Value"`
 	// Web 网页类型属性，扩展属性类型为1时填写
-	Web string `xml:"Web"`
+	Web string `json:"Web" xml:"Web"`
 	// Title 网页的展示标题
-	Title string `xml:"Title"`
+	Title string `json:"Title" xml:"Title"`
 	// Url 网页的url
-	Url string `xml:"Url"`
+	Url string `json:"Url" xml:"Url"`
+}
+
+// rxEventChangeTypeDeleteUser 接受的事件消息，删除成员事件
+type rxEventChangeTypeDeleteUser struct {
+	// UserID 成员UserID
+	UserID string `json:"UserID" xml:"UserID"`
+}
+
+// rxEventChangeTypeCreateParty 接受的事件消息，新增部门事件
+type rxEventChangeTypeCreateParty struct {
+	// ID 部门Id
+	ID int64 `json:"Id" xml:"Id"`
+	// Name 部门名称
+	Name string `json:"Name" xml:"Name"`
+	// ParentID 父部门id
+	ParentID int64 `json:"ParentId" xml:"ParentId"`
+	// Order 部门排序
+	Order uint32 `json:"Order" xml:"Order"`
+}
+
+// rxEventChangeTypeUpdateParty 接受的事件消息，更新部门事件
+type rxEventChangeTypeUpdateParty struct {
+	// ID 部门Id
+	ID int64 `json:"Id" xml:"Id"`
+	// Name 部门名称，仅当该字段发生变更时传递
+	Name string `json:"Name" xml:"Name"`
+	// ParentID 父部门id，仅当该字段发生变更时传递
+	ParentID int64 `json:"ParentId" xml:"ParentId"`
+}
+
+// rxEventChangeTypeDeleteParty 接受的事件消息，删除部门事件
+type rxEventChangeTypeDeleteParty struct {
+	// ID 部门Id
+	ID int64 `json:"Id" xml:"Id"`
+}
+
+// rxEventChangeTypeUpdateTag 接受的事件消息，标签成员变更事件
+type rxEventChangeTypeUpdateTag struct {
+	// TagID 标签Id
+	TagID int64 `json:"TagId" xml:"TagId"`
+	// AddUserItems 标签中新增的成员userid列表，用逗号分隔
+	AddUserItems string `json:"AddUserItems" xml:"AddUserItems"`
+	// DelUserItems 标签中删除的成员userid列表，用逗号分隔
+	DelUserItems string `json:"DelUserItems" xml:"DelUserItems"`
+	// AddPartyItems 标签中新增的部门id列表，用逗号分隔
+	AddPartyItems string `json:"AddPartyItems" xml:"AddPartyItems"`
+	// DelPartyItems 标签中删除的部门id列表，用逗号分隔
+	DelPartyItems string `json:"DelPartyItems" xml:"DelPartyItems"`
 }
 
 // rxEventAppMenuClick 接受的事件消息，应用菜单点击事件
 type rxEventAppMenuClick struct {
 	// EventKey 事件key
-	EventKey string `xml:"EventKey"`
+	EventKey string `json:"EventKey" xml:"EventKey"`
 }
 
 // rxEventAppMenuView 接受的事件消息，应用菜单点击链接事件
 type rxEventAppMenuView struct {
 	// EventKey 事件key
-	EventKey string `xml:"EventKey"`
+	EventKey string `json:"EventKey" xml:"EventKey"`
+}
+
+// rxEventAppMenuScanCodePush 接受的事件消息，扫码推事件
+type rxEventAppMenuScanCodePush struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// ScanType 扫描类型，一般是qrcode
+	ScanType string `json:"ScanType" xml:"ScanCodeInfo>ScanType"`
+	// ScanResult 扫描结果，即二维码对应的字符串信息
+	ScanResult string `json:"ScanResult" xml:"ScanCodeInfo>ScanResult"`
+}
+
+// rxEventAppMenuScanCodeWaitMsg 接受的事件消息，扫码推事件且弹出“消息接收中”提示框
+type rxEventAppMenuScanCodeWaitMsg struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// ScanType 扫描类型，一般是qrcode
+	ScanType string `json:"ScanType" xml:"ScanCodeInfo>ScanType"`
+	// ScanResult 扫描结果，即二维码对应的字符串信息
+	ScanResult string `json:"ScanResult" xml:"ScanCodeInfo>ScanResult"`
+}
+
+// rxEventAppMenuPicSysPhoto 接受的事件消息，弹出系统拍照发图事件
+type rxEventAppMenuPicSysPhoto struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// Count 发送的图片数量
+	Count int `json:"Count" xml:"SendPicsInfo>Count"`
+	// PicList 图片列表
+	PicList []rxSendPicsInfoItem `json:"PicList" xml:"SendPicsInfo>PicList>item"`
+}
+
+// rxEventAppMenuPicPhotoOrAlbum 接受的事件消息，弹出拍照或者相册发图事件
+type rxEventAppMenuPicPhotoOrAlbum struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// Count 发送的图片数量
+	Count int `json:"Count" xml:"SendPicsInfo>Count"`
+	// PicList 图片列表
+	PicList []rxSendPicsInfoItem `json:"PicList" xml:"SendPicsInfo>PicList>item"`
+}
+
+// rxEventAppMenuPicWeixin 接受的事件消息，弹出微信相册发图器事件
+type rxEventAppMenuPicWeixin struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// Count 发送的图片数量
+	Count int `json:"Count" xml:"SendPicsInfo>Count"`
+	// PicList 图片列表
+	PicList []rxSendPicsInfoItem `json:"PicList" xml:"SendPicsInfo>PicList>item"`
+}
+
+// rxSendPicsInfoItem 发图事件中的一张图片
+type rxSendPicsInfoItem struct {
+	// PicMd5Sum 图片的MD5值，开发者若需要，可用于验证接收到图片
+	PicMd5Sum string `json:"PicMd5Sum" xml:"PicMd5Sum"`
+}
+
+// rxEventAppMenuLocationSelect 接受的事件消息，弹出地理位置选择器事件
+type rxEventAppMenuLocationSelect struct {
+	// EventKey 事件key
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// Lat 地理位置纬度
+	Lat float64 `json:"Location_X" xml:"SendLocationInfo>Location_X"`
+	// Lon 地理位置经度
+	Lon float64 `json:"Location_Y" xml:"SendLocationInfo>Location_Y"`
+	// Scale 精度，可理解为精度或者比例尺、越精细的话 scale越高
+	Scale int `json:"Scale" xml:"SendLocationInfo>Scale"`
+	// Label 地理位置的字符串信息
+	Label string `json:"Label" xml:"SendLocationInfo>Label"`
+	// PoiName POI的名字，可能为空
+	PoiName string `json:"Poiname" xml:"SendLocationInfo>Poiname"`
+}
+
+// rxEventAppMenuViewMiniprogram 接受的事件消息，点击菜单跳转小程序事件
+type rxEventAppMenuViewMiniprogram struct {
+	// EventKey 事件key，即跳转的小程序路径
+	EventKey string `json:"EventKey" xml:"EventKey"`
+}
+
+// rxEventTemplateCardEvent 接受的事件消息，模板卡片事件推送
+type rxEventTemplateCardEvent struct {
+	// EventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// TaskID 与发送模板卡片消息时指定的task_id相同
+	TaskID string `json:"TaskId" xml:"TaskId"`
+	// CardType 通用模板卡片的类型，类型有"text_notice", "news_notice", "button_interaction", "vote_interaction", "multiple_interaction"五种
+	CardType string `json:"CardType" xml:"CardType"`
+	// ResponseCode 用于调用更新卡片接口的ResponseCode，72小时内有效，且只能使用一次
+	ResponseCode string `json:"ResponseCode" xml:"ResponseCode"`
+	// SelectedItems 下拉式的选择器选择的选项
+	SelectedItems []rxTemplateCardSelectedItem `json:"SelectedItems" xml:"SelectedItems>SelectedItem"`
+}
+
+// rxTemplateCardSelectedItem 模板卡片事件中下拉式的选择器选择的选项
+type rxTemplateCardSelectedItem struct {
+	// QuestionKey 问题的key值
+	QuestionKey string `json:"QuestionKey" xml:"QuestionKey"`
+	// OptionIDs 对应问题的选项列表
+	OptionIDs []string `json:"OptionIds" xml:"OptionIds>OptionId"`
+}
+
+// rxEventTemplateCardMenuEvent 接受的事件消息，通用模板卡片右上角菜单事件推送
+type rxEventTemplateCardMenuEvent struct {
+	// EventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// TaskID 与发送模板卡片消息时指定的task_id相同
+	TaskID string `json:"TaskId" xml:"TaskId"`
+	// CardType 通用模板卡片的类型
+	CardType string `json:"CardType" xml:"CardType"`
+	// ResponseCode 用于调用更新卡片接口的ResponseCode，72小时内有效，且只能使用一次
+	ResponseCode string `json:"ResponseCode" xml:"ResponseCode"`
+}
+
+// rxEventTaskCardClick 接受的事件消息，任务卡片事件推送
+type rxEventTaskCardClick struct {
+	// EventKey 与发送任务卡片消息时指定的按钮btn:key值相同
+	EventKey string `json:"EventKey" xml:"EventKey"`
+	// TaskID 与发送任务卡片消息时指定的task_id相同
+	TaskID string `json:"TaskId" xml:"TaskId"`
+}
+
+// rxEventEnterAgent 接受的事件消息，进入应用
+type rxEventEnterAgent struct {
+	// EventKey 事件KEY值，此事件该值为空
+	EventKey string `json:"EventKey" xml:"EventKey"`
+}
+
+// rxEventLocation 接受的事件消息，上报地理位置
+type rxEventLocation struct {
+	// Latitude 地理位置纬度
+	Latitude float64 `json:"Latitude" xml:"Latitude"`
+	// Longitude 地理位置经度
+	Longitude float64 `json:"Longitude" xml:"Longitude"`
+	// Precision 地理位置精度
+	Precision float64 `json:"Precision" xml:"Precision"`
+	// AppType app类型，在企业微信固定返回wxwork，在微信不返回该字段
+	AppType string `json:"AppType" xml:"AppType"`
+}
+
+// rxEventBatchJobResult 接受的事件消息，异步任务完成通知
+type rxEventBatchJobResult struct {
+	// JobID 异步任务id
+	JobID string `json:"JobId" xml:"BatchJob>JobId"`
+	// JobType 操作类型，字符串，目前分别有：sync_user(增量更新成员)、 replace_user(全量覆盖成员）、invite_user(邀请成员关注）、replace_party(全量覆盖部门)
+	JobType string `json:"JobType" xml:"BatchJob>JobType"`
+	// ErrCode 返回码
+	ErrCode int `json:"ErrCode" xml:"BatchJob>ErrCode"`
+	// ErrMsg 对返回码的文本描述内容
+	ErrMsg string `json:"ErrMsg" xml:"BatchJob>ErrMsg"`
+}
+
+// rxEventOpenApprovalChange 接受的事件消息，审批状态通知事件
+type rxEventOpenApprovalChange struct {
+	// ApprovalInfo 审批信息
+	ApprovalInfo OpenApprovalInfo `json:"ApprovalInfo" xml:"ApprovalInfo"`
+}
+
+// OpenApprovalInfo 自建应用审批的审批信息
+type OpenApprovalInfo struct {
+	// ThirdNo 审批单编号，由开发者在发起申请时自定义
+	ThirdNo string `json:"ThirdNo" xml:"ThirdNo"`
+	// OpenSpName 审批模板名称
+	OpenSpName string `json:"OpenSpName" xml:"OpenSpName"`
+	// OpenTemplateID 审批模板id
+	OpenTemplateID string `json:"OpenTemplateId" xml:"OpenTemplateId"`
+	// OpenSpStatus 申请单当前审批状态：1-审批中；2-已通过；3-已驳回；4-已取消
+	OpenSpStatus int `json:"OpenSpStatus" xml:"OpenSpStatus"`
+	// ApplyTime 提交申请时间
+	ApplyTime int64 `json:"ApplyTime" xml:"ApplyTime"`
+	// ApplyUserName 提交者姓名
+	ApplyUserName string `json:"ApplyUserName" xml:"ApplyUserName"`
+	// ApplyUserID 提交者userid
+	ApplyUserID string `json:"ApplyUserId" xml:"ApplyUserId"`
+	// ApplyUserParty 提交者所在部门
+	ApplyUserParty string `json:"ApplyUserParty" xml:"ApplyUserParty"`
+	// ApplyUserImage 提交者头像
+	ApplyUserImage string `json:"ApplyUserImage" xml:"ApplyUserImage"`
+	// ApprovalNodes 审批流程信息
+	ApprovalNodes []OpenApprovalNode `json:"ApprovalNodes" xml:"ApprovalNodes>ApprovalNode"`
+	// NotifyNodes 抄送信息，可能有多个抄送人
+	NotifyNodes []OpenApprovalNotifyNode `json:"NotifyNodes" xml:"NotifyNodes>NotifyNode"`
+	// ApproverStep 当前审批节点：0-第一个审批节点；1-第二个审批节点…以此类推
+	ApproverStep int `json:"approverstep" xml:"approverstep"`
+}
+
+// OpenApprovalNode 自建应用审批的审批节点
+type OpenApprovalNode struct {
+	// NodeStatus 节点审批操作状态：1-审批中；2-已同意；3-已驳回；4-已转审
+	NodeStatus int `json:"NodeStatus" xml:"NodeStatus"`
+	// NodeAttr 审批节点属性：1-或签；2-会签
+	NodeAttr int `json:"NodeAttr" xml:"NodeAttr"`
+	// NodeType 审批节点类型：1-固定成员；2-标签；3-上级
+	NodeType int `json:"NodeType" xml:"NodeType"`
+	// Items 审批节点信息，当节点为标签或上级时，一个节点可能有多个分支
+	Items []OpenApprovalNodeItem `json:"Items" xml:"Items>Item"`
+}
+
+// OpenApprovalNodeItem 自建应用审批的审批节点分支
+type OpenApprovalNodeItem struct {
+	// ItemName 分支审批人姓名
+	ItemName string `json:"ItemName" xml:"ItemName"`
+	// ItemUserID 分支审批人userid
+	ItemUserID string `json:"ItemUserId" xml:"ItemUserId"`
+	// ItemParty 分支审批人所在部门
+	ItemParty string `json:"ItemParty" xml:"ItemParty"`
+	// ItemImage 分支审批人头像
+	ItemImage string `json:"ItemImage" xml:"ItemImage"`
+	// ItemStatus 分支审批审批操作状态：1-审批中；2-已同意；3-已驳回；4-已转审
+	ItemStatus int `json:"ItemStatus" xml:"ItemStatus"`
+	// ItemSpeech 分支审批人审批意见
+	ItemSpeech string `json:"ItemSpeech" xml:"ItemSpeech"`
+	// ItemOpTime 分支审批人操作时间
+	ItemOpTime int64 `json:"ItemOpTime" xml:"ItemOpTime"`
+}
+
+// OpenApprovalNotifyNode 自建应用审批的抄送人
+type OpenApprovalNotifyNode struct {
+	// ItemName 抄送人姓名
+	ItemName string `json:"ItemName" xml:"ItemName"`
+	// ItemUserID 抄送人userid
+	ItemUserID string `json:"ItemUserId" xml:"ItemUserId"`
+	// ItemParty 抄送人所在部门
+	ItemParty string `json:"ItemParty" xml:"ItemParty"`
+	// ItemImage 抄送人头像
+	ItemImage string `json:"ItemImage" xml:"ItemImage"`
 }
 
 // rxEventAppSubscribe 接受的事件消息，用户订阅事件
 type rxEventAppSubscribe struct {
 	// EventKey 事件key
-	EventKey string `xml:"EventKey"`
+	EventKey string `json:"EventKey" xml:"EventKey"`
 }
 
 // rxEventAppUnsubscribe 接受的事件消息，用户取消订阅事件
 type rxEventAppUnsubscribe struct {
 	// EventKey 事件key
-	EventKey string `xml:"EventKey"`
+	EventKey string `json:"EventKey" xml:"EventKey"`
 }
 
 // rxEventKfMsgOrEvent 接受的事件消息，客服接收消息和事件
 type rxEventKfMsgOrEvent struct {
 	// OpenKfID 有新消息的客服账号。可通过sync_msg接口指定open_kfid获取此客服账号的消息
-	OpenKfID string `xml:"OpenKfId"`
+	OpenKfID string `json:"OpenKfId" xml:"OpenKfId"`
 	// Token 调用拉取消息接口时，需要传此token，用于校验请求的合法性
-	Token string `xml:"Token"`
+	Token string `json:"Token" xml:"Token"`
 }
 
 // rxEventUnknown 接受的事件消息，未定义的事件类型
 type rxEventUnknown struct {
 	// EventType 事件类型
-	EventType string `xml:"-"`
+	EventType string `json:"-" xml:"-"`
 	// Raw 原始的消息体
-	Raw string `xml:"-"`
+	Raw string `json:"-" xml:"-"`
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 // NOTE: 这顺便就构成了一个封闭的 enum
@@ -145,25 +147,15 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 				return nil, err
 			}
 			return &x, nil
-		case EventTypeChangeContact:
-			switch common.ChangeType {
-			case ChangeTypeUpdateUser:
-				var x rxEventChangeTypeUpdateUser
-				err := xml.Unmarshal(body, &x)
-				if err != nil {
-					return nil, err
-				}
-				return &x, nil
-			case ChangeTypeCreateUser:
-				var x rxEventChangeTypeCreateUser
-				err := xml.Unmarshal(body, &x)
-				if err != nil {
-					return nil, err
-				}
-				return &x, nil
-			default:
-				return nil, fmt.Errorf("unknown change type '%s'", common.ChangeType)
+		case EventTypeChangeExternalTag:
+			var x rxEventChangeExternalTag
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
 			}
+			return &x, nil
+		case EventTypeChangeContact:
+			return extractChangeContactExtras(common.ChangeType, body)
 		case EventTypeAppMenuClick:
 			var x rxEventAppMenuClick
 			err := xml.Unmarshal(body, &x)
@@ -178,6 +170,118 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 				return nil, err
 			}
 			return &x, nil
+		case EventTypeAppMenuScanCodePush:
+			var x rxEventAppMenuScanCodePush
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuScanCodeWaitMsg:
+			var x rxEventAppMenuScanCodeWaitMsg
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuPicSysPhoto:
+			var x rxEventAppMenuPicSysPhoto
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuPicPhotoOrAlbum:
+			var x rxEventAppMenuPicPhotoOrAlbum
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuPicWeixin:
+			var x rxEventAppMenuPicWeixin
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuLocationSelect:
+			var x rxEventAppMenuLocationSelect
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppMenuViewMiniprogram:
+			var x rxEventAppMenuViewMiniprogram
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeTemplateCardEvent:
+			var x rxEventTemplateCardEvent
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeTemplateCardMenuEvent:
+			var x rxEventTemplateCardMenuEvent
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeTaskCardClick:
+			var x rxEventTaskCardClick
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeEnterAgent:
+			var x rxEventEnterAgent
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeLocation:
+			var x rxEventLocation
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeBatchJobResult:
+			var x rxEventBatchJobResult
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeOpenApprovalChange:
+			var x rxEventOpenApprovalChange
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppSubscribe:
+			var x rxEventAppSubscribe
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+		case EventTypeAppUnsubscribe:
+			var x rxEventAppUnsubscribe
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
 		case EventTypeKfMsgOrEvent:
 			var x rxEventKfMsgOrEvent
 			err := xml.Unmarshal(body, &x)
@@ -196,6 +300,63 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 	}
 }
 
+// extractChangeContactExtras 解析通讯录变更事件，企业回调与第三方应用指令回调共用
+func extractChangeContactExtras(changeType ChangeType, body []byte) (messageKind, error) {
+	switch changeType {
+	case ChangeTypeUpdateUser:
+		var x rxEventChangeTypeUpdateUser
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeCreateUser:
+		var x rxEventChangeTypeCreateUser
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeDeleteUser:
+		var x rxEventChangeTypeDeleteUser
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeCreateParty:
+		var x rxEventChangeTypeCreateParty
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeUpdateParty:
+		var x rxEventChangeTypeUpdateParty
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeDeleteParty:
+		var x rxEventChangeTypeDeleteParty
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	case ChangeTypeUpdateTag:
+		var x rxEventChangeTypeUpdateTag
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	default:
+		return nil, fmt.Errorf("unknown change type '%s'", changeType)
+	}
+}
+
 // TextMessageExtras 文本消息的参数。
 type TextMessageExtras interface {
 	messageKind
@@ -632,6 +793,27 @@ type EventChangeExternalChat interface {
 
 	// GetFailReason 接替失败的原因, customer_refused-客户拒绝， customer_limit_exceed-接替成员的客户数达到上限
 	GetFailReason() string
+
+	// GetUpdateDetail 变更详情，ChangeType为update时存在
+	GetUpdateDetail() ExternalChatUpdateDetail
+
+	// GetJoinScene 成员的入群方式：0-由成员邀请入群；3-通过扫描群二维码入群
+	GetJoinScene() int
+
+	// GetQuitScene 成员的退群方式：0-自己退群；1-群主/群管理员移出
+	GetQuitScene() int
+
+	// GetMemChangeCnt 成员入群或退群时的成员变更数量
+	GetMemChangeCnt() int
+
+	// GetMemChangeList 成员入群或退群时变更的成员列表
+	GetMemChangeList() []string
+
+	// GetLastMemVer 成员入群或退群时，变更前的群成员版本号
+	GetLastMemVer() string
+
+	// GetCurMemVer 成员入群或退群时，变更后的群成员版本号
+	GetCurMemVer() string
 }
 
 var _ EventChangeExternalChat = (*rxEventChangeExternalChat)(nil)
@@ -639,11 +821,18 @@ var _ EventChangeExternalChat = (*rxEventChangeExternalChat)(nil)
 func (r *rxEventChangeExternalChat) formatInto(w io.Writer) {
 	_, _ = fmt.Fprintf(
 		w,
-		"ChatID: %#v, ToUserName: %#v, FromUserName: %#v, FailReason: %#v",
+		"ChatID: %#v, ToUserName: %#v, FromUserName: %#v, FailReason: %#v, UpdateDetail: %#v, JoinScene: %d, QuitScene: %d, MemChangeCnt: %d, MemChangeList: %#v, LastMemVer: %#v, CurMemVer: %#v",
 		r.ChatID,
 		r.ToUserName,
 		r.FromUserName,
 		r.FailReason,
+		r.UpdateDetail,
+		r.JoinScene,
+		r.QuitScene,
+		r.MemChangeCnt,
+		r.MemChangeList,
+		r.LastMemVer,
+		r.CurMemVer,
 	)
 }
 
@@ -663,6 +852,72 @@ func (r *rxEventChangeExternalChat) GetFailReason() string {
 	return r.FailReason
 }
 
+func (r *rxEventChangeExternalChat) GetUpdateDetail() ExternalChatUpdateDetail {
+	return r.UpdateDetail
+}
+
+func (r *rxEventChangeExternalChat) GetJoinScene() int {
+	return r.JoinScene
+}
+
+func (r *rxEventChangeExternalChat) GetQuitScene() int {
+	return r.QuitScene
+}
+
+func (r *rxEventChangeExternalChat) GetMemChangeCnt() int {
+	return r.MemChangeCnt
+}
+
+func (r *rxEventChangeExternalChat) GetMemChangeList() []string {
+	return r.MemChangeList
+}
+
+func (r *rxEventChangeExternalChat) GetLastMemVer() string {
+	return r.LastMemVer
+}
+
+func (r *rxEventChangeExternalChat) GetCurMemVer() string {
+	return r.CurMemVer
+}
+
+// EventChangeExternalTag 企业客户标签变更事件
+type EventChangeExternalTag interface {
+	messageKind
+
+	// GetID 标签或标签组的ID，ChangeType为shuffle时为标签组ID，为空表示对所有标签组重排
+	GetID() string
+
+	// GetTagType 创建、变更、删除时为标签或标签组，tag-标签，tag_group-标签组
+	GetTagType() string
+
+	// GetStrategyID 标签或标签组所属的规则组id，只有规则组标签会有此字段
+	GetStrategyID() int64
+}
+
+var _ EventChangeExternalTag = (*rxEventChangeExternalTag)(nil)
+
+func (r *rxEventChangeExternalTag) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %#v, TagType: %#v, StrategyID: %d",
+		r.ID,
+		r.TagType,
+		r.StrategyID,
+	)
+}
+
+func (r *rxEventChangeExternalTag) GetID() string {
+	return r.ID
+}
+
+func (r *rxEventChangeExternalTag) GetTagType() string {
+	return r.TagType
+}
+
+func (r *rxEventChangeExternalTag) GetStrategyID() int64 {
+	return r.StrategyID
+}
+
 // EventSysApprovalChange 审批申请状态变化回调通知
 type EventSysApprovalChange interface {
 	messageKind
@@ -683,6 +938,78 @@ func (r rxEventSysApprovalChange) GetApprovalInfo() OAApprovalInfo {
 	return r.ApprovalInfo
 }
 
+// EventChangeTypeCreateUser 新增成员事件
+type EventChangeTypeCreateUser interface {
+	messageKind
+
+	// GetUserID 成员UserID
+	GetUserID() string
+
+	// GetName 成员名称
+	GetName() string
+
+	// GetDepartment 成员部门列表，仅包含该应用有查看权限的部门id
+	GetDepartment() []int64
+
+	// GetIsLeaderInDept 所在部门是否为上级，顺序与 GetDepartment 的部门逐一对应
+	GetIsLeaderInDept() []bool
+
+	// GetMobile 手机号
+	GetMobile() string
+
+	// GetPosition 职位信息
+	GetPosition() string
+
+	// GetGender 性别，1表示男性，2表示女性
+	GetGender() int
+
+	// GetEmail 邮箱
+	GetEmail() string
+
+	// GetStatus 激活状态：1=已激活 2=已禁用 4=未激活 5=成员退出
+	GetStatus() int
+
+	// GetAvatar 头像url
+	GetAvatar() string
+
+	// GetAlias 成员别名
+	GetAlias() string
+
+	// GetTelephone 座机
+	GetTelephone() string
+
+	// GetAddress 地址
+	GetAddress() string
+}
+
+// EventChangeTypeUpdateUser 更新成员事件
+//
+// 只有变更了的字段会推送，未推送的字段取零值。
+type EventChangeTypeUpdateUser interface {
+	EventChangeTypeCreateUser
+
+	// GetNewUserID 新的UserID，变更时推送（userid由系统生成时可更改一次）
+	GetNewUserID() string
+}
+
+var _ EventChangeTypeCreateUser = (*rxEventChangeTypeCreateUser)(nil)
+var _ EventChangeTypeUpdateUser = (*rxEventChangeTypeUpdateUser)(nil)
+
+// splitIsLeaderInDept 拆分逗号分隔的是否为部门上级列表
+func splitIsLeaderInDept(x string) []bool {
+	items := splitCommaList(x)
+	if items == nil {
+		return nil
+	}
+
+	result := make([]bool, len(items))
+	for i, item := range items {
+		result[i] = item == "1"
+	}
+
+	return result
+}
+
 func (r rxEventChangeTypeUpdateUser) formatInto(w io.Writer) {
 	_, _ = fmt.Fprintf(
 		w,
@@ -691,60 +1018,950 @@ func (r rxEventChangeTypeUpdateUser) formatInto(w io.Writer) {
 	)
 }
 
-func (r rxEventChangeTypeCreateUser) formatInto(w io.Writer) {
-	_, _ = fmt.Fprintf(
-		w,
-		"CreateUser: %#v",
-		r,
-	)
+func (r *rxEventChangeTypeUpdateUser) GetUserID() string {
+	return r.UserID
 }
 
-func (r rxEventAppMenuClick) formatInto(w io.Writer) {
-	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+func (r *rxEventChangeTypeUpdateUser) GetNewUserID() string {
+	return r.NewUserID
 }
 
-func (r rxEventAppMenuView) formatInto(w io.Writer) {
-	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+func (r *rxEventChangeTypeUpdateUser) GetName() string {
+	return r.Name
 }
 
-func (r rxEventAppSubscribe) formatInto(w io.Writer) {
-	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+func (r *rxEventChangeTypeUpdateUser) GetDepartment() []int64 {
+	return splitCommaInt64List(r.Department)
 }
 
-func (r rxEventAppUnsubscribe) formatInto(w io.Writer) {
-	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+func (r *rxEventChangeTypeUpdateUser) GetIsLeaderInDept() []bool {
+	return splitIsLeaderInDept(r.IsLeaderInDept)
 }
 
-// EventKfMsgOrEvent 客服接收消息和事件
-type EventKfMsgOrEvent interface {
-	messageKind
+func (r *rxEventChangeTypeUpdateUser) GetMobile() string {
+	return r.Mobile
+}
 
-	// GetOpenKfID 客服账号ID
-	GetOpenKfID() string
+func (r *rxEventChangeTypeUpdateUser) GetPosition() string {
+	return r.Position
+}
 
-	// GetToken 调用拉取消息接口时，需要传此token，用于校验请求的合法性
-	GetToken() string
+func (r *rxEventChangeTypeUpdateUser) GetGender() int {
+	return r.Gender
 }
 
-var _ EventKfMsgOrEvent = (*rxEventKfMsgOrEvent)(nil)
+func (r *rxEventChangeTypeUpdateUser) GetEmail() string {
+	return r.Email
+}
 
-func (r *rxEventKfMsgOrEvent) formatInto(w io.Writer) {
+func (r *rxEventChangeTypeUpdateUser) GetStatus() int {
+	return r.Status
+}
+
+func (r *rxEventChangeTypeUpdateUser) GetAvatar() string {
+	return r.Avatar
+}
+
+func (r *rxEventChangeTypeUpdateUser) GetAlias() string {
+	return r.Alias
+}
+
+func (r *rxEventChangeTypeUpdateUser) GetTelephone() string {
+	return r.Telephone
+}
+
+func (r *rxEventChangeTypeUpdateUser) GetAddress() string {
+	return r.Address
+}
+
+func (r rxEventChangeTypeCreateUser) formatInto(w io.Writer) {
 	_, _ = fmt.Fprintf(
 		w,
-		"OpenKfID: %#v, Token: %#v",
-		r.OpenKfID,
-		r.Token,
+		"CreateUser: %#v",
+		r,
 	)
 }
 
-func (r *rxEventKfMsgOrEvent) GetOpenKfID() string {
-	return r.OpenKfID
+func (r *rxEventChangeTypeCreateUser) GetUserID() string {
+	return r.UserID
 }
 
-func (r *rxEventKfMsgOrEvent) GetToken() string {
-	return r.Token
+func (r *rxEventChangeTypeCreateUser) GetName() string {
+	return r.Name
 }
 
+func (r *rxEventChangeTypeCreateUser) GetDepartment() []int64 {
+	return splitCommaInt64List(r.Department)
+}
+
+func (r *rxEventChangeTypeCreateUser) GetIsLeaderInDept() []bool {
+	return splitIsLeaderInDept(r.IsLeaderInDept)
+}
+
+func (r *rxEventChangeTypeCreateUser) GetMobile() string {
+	return r.Mobile
+}
+
+func (r *rxEventChangeTypeCreateUser) GetPosition() string {
+	return r.Position
+}
+
+func (r *rxEventChangeTypeCreateUser) GetGender() int {
+	return r.Gender
+}
+
+func (r *rxEventChangeTypeCreateUser) GetEmail() string {
+	return r.Email
+}
+
+func (r *rxEventChangeTypeCreateUser) GetStatus() int {
+	return r.Status
+}
+
+func (r *rxEventChangeTypeCreateUser) GetAvatar() string {
+	return r.Avatar
+}
+
+func (r *rxEventChangeTypeCreateUser) GetAlias() string {
+	return r.Alias
+}
+
+func (r *rxEventChangeTypeCreateUser) GetTelephone() string {
+	return r.Telephone
+}
+
+func (r *rxEventChangeTypeCreateUser) GetAddress() string {
+	return r.Address
+}
+
+// EventChangeTypeDeleteUser 删除成员事件
+type EventChangeTypeDeleteUser interface {
+	messageKind
+
+	// GetUserID 成员UserID
+	GetUserID() string
+}
+
+var _ EventChangeTypeDeleteUser = (*rxEventChangeTypeDeleteUser)(nil)
+
+func (r *rxEventChangeTypeDeleteUser) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "UserID: %#v", r.UserID)
+}
+
+func (r *rxEventChangeTypeDeleteUser) GetUserID() string {
+	return r.UserID
+}
+
+// EventChangeTypeCreateParty 新增部门事件
+type EventChangeTypeCreateParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+
+	// GetName 部门名称
+	GetName() string
+
+	// GetParentID 父部门id
+	GetParentID() int64
+
+	// GetOrder 部门排序
+	GetOrder() uint32
+}
+
+var _ EventChangeTypeCreateParty = (*rxEventChangeTypeCreateParty)(nil)
+
+func (r *rxEventChangeTypeCreateParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %d, Name: %#v, ParentID: %d, Order: %d",
+		r.ID,
+		r.Name,
+		r.ParentID,
+		r.Order,
+	)
+}
+
+func (r *rxEventChangeTypeCreateParty) GetID() int64 {
+	return r.ID
+}
+
+func (r *rxEventChangeTypeCreateParty) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeTypeCreateParty) GetParentID() int64 {
+	return r.ParentID
+}
+
+func (r *rxEventChangeTypeCreateParty) GetOrder() uint32 {
+	return r.Order
+}
+
+// EventChangeTypeUpdateParty 更新部门事件
+type EventChangeTypeUpdateParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+
+	// GetName 部门名称，仅当该字段发生变更时不为空
+	GetName() string
+
+	// GetParentID 父部门id，仅当该字段发生变更时不为零
+	GetParentID() int64
+}
+
+var _ EventChangeTypeUpdateParty = (*rxEventChangeTypeUpdateParty)(nil)
+
+func (r *rxEventChangeTypeUpdateParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %d, Name: %#v, ParentID: %d",
+		r.ID,
+		r.Name,
+		r.ParentID,
+	)
+}
+
+func (r *rxEventChangeTypeUpdateParty) GetID() int64 {
+	return r.ID
+}
+
+func (r *rxEventChangeTypeUpdateParty) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeTypeUpdateParty) GetParentID() int64 {
+	return r.ParentID
+}
+
+// EventChangeTypeDeleteParty 删除部门事件
+type EventChangeTypeDeleteParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+}
+
+var _ EventChangeTypeDeleteParty = (*rxEventChangeTypeDeleteParty)(nil)
+
+func (r *rxEventChangeTypeDeleteParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "ID: %d", r.ID)
+}
+
+func (r *rxEventChangeTypeDeleteParty) GetID() int64 {
+	return r.ID
+}
+
+// EventChangeTypeUpdateTag 标签成员变更事件
+type EventChangeTypeUpdateTag interface {
+	messageKind
+
+	// GetTagID 标签Id
+	GetTagID() int64
+
+	// GetAddUserItems 标签中新增的成员userid列表
+	GetAddUserItems() []string
+
+	// GetDelUserItems 标签中删除的成员userid列表
+	GetDelUserItems() []string
+
+	// GetAddPartyItems 标签中新增的部门id列表
+	GetAddPartyItems() []int64
+
+	// GetDelPartyItems 标签中删除的部门id列表
+	GetDelPartyItems() []int64
+}
+
+var _ EventChangeTypeUpdateTag = (*rxEventChangeTypeUpdateTag)(nil)
+
+func (r *rxEventChangeTypeUpdateTag) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"TagID: %d, AddUserItems: %#v, DelUserItems: %#v, AddPartyItems: %#v, DelPartyItems: %#v",
+		r.TagID,
+		r.AddUserItems,
+		r.DelUserItems,
+		r.AddPartyItems,
+		r.DelPartyItems,
+	)
+}
+
+func (r *rxEventChangeTypeUpdateTag) GetTagID() int64 {
+	return r.TagID
+}
+
+func (r *rxEventChangeTypeUpdateTag) GetAddUserItems() []string {
+	return splitCommaList(r.AddUserItems)
+}
+
+func (r *rxEventChangeTypeUpdateTag) GetDelUserItems() []string {
+	return splitCommaList(r.DelUserItems)
+}
+
+func (r *rxEventChangeTypeUpdateTag) GetAddPartyItems() []int64 {
+	return splitCommaInt64List(r.AddPartyItems)
+}
+
+func (r *rxEventChangeTypeUpdateTag) GetDelPartyItems() []int64 {
+	return splitCommaInt64List(r.DelPartyItems)
+}
+
+// splitCommaList 拆分逗号分隔的列表，忽略空项
+func splitCommaList(x string) []string {
+	var result []string
+	for _, item := range strings.Split(x, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// splitCommaInt64List 拆分逗号分隔的整数列表，忽略空项与无法解析的项
+func splitCommaInt64List(x string) []int64 {
+	var result []int64
+	for _, item := range splitCommaList(x) {
+		n, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, n)
+	}
+
+	return result
+}
+
+// EventAppMenuClick 点击菜单拉取消息事件
+type EventAppMenuClick interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，与自定义菜单接口中KEY值对应
+	GetEventKey() string
+}
+
+var _ EventAppMenuClick = (*rxEventAppMenuClick)(nil)
+
+func (r rxEventAppMenuClick) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventAppMenuClick) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventAppMenuView 点击菜单跳转链接事件
+type EventAppMenuView interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，设置的跳转URL
+	GetEventKey() string
+}
+
+var _ EventAppMenuView = (*rxEventAppMenuView)(nil)
+
+func (r rxEventAppMenuView) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventAppMenuView) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventAppSubscribe 成员关注事件
+type EventAppSubscribe interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，一般为空
+	GetEventKey() string
+}
+
+var _ EventAppSubscribe = (*rxEventAppSubscribe)(nil)
+
+func (r rxEventAppSubscribe) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventAppSubscribe) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventAppUnsubscribe 成员取消关注事件
+type EventAppUnsubscribe interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，一般为空
+	GetEventKey() string
+}
+
+var _ EventAppUnsubscribe = (*rxEventAppUnsubscribe)(nil)
+
+func (r rxEventAppUnsubscribe) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventAppUnsubscribe) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventAppMenuScanCode 扫码推事件的参数，扫码推事件与扫码推事件且弹出“消息接收中”提示框共用
+type EventAppMenuScanCode interface {
+	messageKind
+
+	// GetEventKey 事件key，与自定义菜单创建时的key值对应
+	GetEventKey() string
+
+	// GetScanType 扫描类型，一般是qrcode
+	GetScanType() string
+
+	// GetScanResult 扫描结果，即二维码对应的字符串信息
+	GetScanResult() string
+}
+
+var _ EventAppMenuScanCode = (*rxEventAppMenuScanCodePush)(nil)
+var _ EventAppMenuScanCode = (*rxEventAppMenuScanCodeWaitMsg)(nil)
+
+func (r *rxEventAppMenuScanCodePush) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, ScanType: %#v, ScanResult: %#v",
+		r.EventKey,
+		r.ScanType,
+		r.ScanResult,
+	)
+}
+
+func (r *rxEventAppMenuScanCodePush) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuScanCodePush) GetScanType() string {
+	return r.ScanType
+}
+
+func (r *rxEventAppMenuScanCodePush) GetScanResult() string {
+	return r.ScanResult
+}
+
+func (r *rxEventAppMenuScanCodeWaitMsg) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, ScanType: %#v, ScanResult: %#v",
+		r.EventKey,
+		r.ScanType,
+		r.ScanResult,
+	)
+}
+
+func (r *rxEventAppMenuScanCodeWaitMsg) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuScanCodeWaitMsg) GetScanType() string {
+	return r.ScanType
+}
+
+func (r *rxEventAppMenuScanCodeWaitMsg) GetScanResult() string {
+	return r.ScanResult
+}
+
+// EventAppMenuPics 发图事件的参数，系统拍照、拍照或相册、微信相册三种发图事件共用
+type EventAppMenuPics interface {
+	messageKind
+
+	// GetEventKey 事件key，与自定义菜单创建时的key值对应
+	GetEventKey() string
+
+	// GetCount 发送的图片数量
+	GetCount() int
+
+	// GetPicMd5Sums 各图片的MD5值，可用于验证接收到的图片
+	GetPicMd5Sums() []string
+}
+
+var _ EventAppMenuPics = (*rxEventAppMenuPicSysPhoto)(nil)
+var _ EventAppMenuPics = (*rxEventAppMenuPicPhotoOrAlbum)(nil)
+var _ EventAppMenuPics = (*rxEventAppMenuPicWeixin)(nil)
+
+// picMd5Sums 取出图片列表中的MD5值
+func picMd5Sums(items []rxSendPicsInfoItem) []string {
+	result := make([]string, len(items))
+	for i, x := range items {
+		result[i] = x.PicMd5Sum
+	}
+
+	return result
+}
+
+func (r *rxEventAppMenuPicSysPhoto) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Count: %d, PicMd5Sums: %#v",
+		r.EventKey,
+		r.Count,
+		picMd5Sums(r.PicList),
+	)
+}
+
+func (r *rxEventAppMenuPicSysPhoto) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuPicSysPhoto) GetCount() int {
+	return r.Count
+}
+
+func (r *rxEventAppMenuPicSysPhoto) GetPicMd5Sums() []string {
+	return picMd5Sums(r.PicList)
+}
+
+func (r *rxEventAppMenuPicPhotoOrAlbum) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Count: %d, PicMd5Sums: %#v",
+		r.EventKey,
+		r.Count,
+		picMd5Sums(r.PicList),
+	)
+}
+
+func (r *rxEventAppMenuPicPhotoOrAlbum) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuPicPhotoOrAlbum) GetCount() int {
+	return r.Count
+}
+
+func (r *rxEventAppMenuPicPhotoOrAlbum) GetPicMd5Sums() []string {
+	return picMd5Sums(r.PicList)
+}
+
+func (r *rxEventAppMenuPicWeixin) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Count: %d, PicMd5Sums: %#v",
+		r.EventKey,
+		r.Count,
+		picMd5Sums(r.PicList),
+	)
+}
+
+func (r *rxEventAppMenuPicWeixin) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuPicWeixin) GetCount() int {
+	return r.Count
+}
+
+func (r *rxEventAppMenuPicWeixin) GetPicMd5Sums() []string {
+	return picMd5Sums(r.PicList)
+}
+
+// EventAppMenuLocationSelect 弹出地理位置选择器事件的参数
+type EventAppMenuLocationSelect interface {
+	messageKind
+
+	// GetEventKey 事件key，与自定义菜单创建时的key值对应
+	GetEventKey() string
+
+	// GetLatitude 地理位置纬度
+	GetLatitude() float64
+
+	// GetLongitude 地理位置经度
+	GetLongitude() float64
+
+	// GetScale 精度，可理解为精度或者比例尺、越精细的话 scale越高
+	GetScale() int
+
+	// GetLabel 地理位置的字符串信息
+	GetLabel() string
+
+	// GetPoiName POI的名字，可能为空
+	GetPoiName() string
+}
+
+var _ EventAppMenuLocationSelect = (*rxEventAppMenuLocationSelect)(nil)
+
+func (r *rxEventAppMenuLocationSelect) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Lat: %#v, Lon: %#v, Scale: %d, Label: %#v, PoiName: %#v",
+		r.EventKey,
+		r.Lat,
+		r.Lon,
+		r.Scale,
+		r.Label,
+		r.PoiName,
+	)
+}
+
+func (r *rxEventAppMenuLocationSelect) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventAppMenuLocationSelect) GetLatitude() float64 {
+	return r.Lat
+}
+
+func (r *rxEventAppMenuLocationSelect) GetLongitude() float64 {
+	return r.Lon
+}
+
+func (r *rxEventAppMenuLocationSelect) GetScale() int {
+	return r.Scale
+}
+
+func (r *rxEventAppMenuLocationSelect) GetLabel() string {
+	return r.Label
+}
+
+func (r *rxEventAppMenuLocationSelect) GetPoiName() string {
+	return r.PoiName
+}
+
+// EventAppMenuViewMiniprogram 点击菜单跳转小程序事件的参数
+type EventAppMenuViewMiniprogram interface {
+	messageKind
+
+	// GetEventKey 事件key，即跳转的小程序路径
+	GetEventKey() string
+}
+
+var _ EventAppMenuViewMiniprogram = (*rxEventAppMenuViewMiniprogram)(nil)
+
+func (r *rxEventAppMenuViewMiniprogram) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventAppMenuViewMiniprogram) GetEventKey() string {
+	return r.EventKey
+}
+
+// TemplateCardSelectedItem 模板卡片事件中下拉式的选择器选择的选项
+type TemplateCardSelectedItem struct {
+	// QuestionKey 问题的key值
+	QuestionKey string
+	// OptionIDs 对应问题的选项列表
+	OptionIDs []string
+}
+
+// EventTemplateCardEvent 模板卡片事件推送
+type EventTemplateCardEvent interface {
+	messageKind
+
+	// GetEventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	GetEventKey() string
+
+	// GetTaskID 与发送模板卡片消息时指定的task_id相同
+	GetTaskID() string
+
+	// GetCardType 通用模板卡片的类型
+	GetCardType() string
+
+	// GetResponseCode 用于调用更新卡片接口的ResponseCode，72小时内有效，且只能使用一次
+	GetResponseCode() string
+
+	// GetSelectedItems 下拉式的选择器选择的选项
+	GetSelectedItems() []TemplateCardSelectedItem
+}
+
+var _ EventTemplateCardEvent = (*rxEventTemplateCardEvent)(nil)
+
+func (r *rxEventTemplateCardEvent) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, TaskID: %#v, CardType: %#v, ResponseCode: %#v, SelectedItems: %#v",
+		r.EventKey,
+		r.TaskID,
+		r.CardType,
+		r.ResponseCode,
+		r.GetSelectedItems(),
+	)
+}
+
+func (r *rxEventTemplateCardEvent) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventTemplateCardEvent) GetTaskID() string {
+	return r.TaskID
+}
+
+func (r *rxEventTemplateCardEvent) GetCardType() string {
+	return r.CardType
+}
+
+func (r *rxEventTemplateCardEvent) GetResponseCode() string {
+	return r.ResponseCode
+}
+
+func (r *rxEventTemplateCardEvent) GetSelectedItems() []TemplateCardSelectedItem {
+	result := make([]TemplateCardSelectedItem, len(r.SelectedItems))
+	for i, x := range r.SelectedItems {
+		result[i] = TemplateCardSelectedItem{
+			QuestionKey: x.QuestionKey,
+			OptionIDs:   x.OptionIDs,
+		}
+	}
+
+	return result
+}
+
+// EventTemplateCardMenuEvent 通用模板卡片右上角菜单事件推送
+type EventTemplateCardMenuEvent interface {
+	messageKind
+
+	// GetEventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	GetEventKey() string
+
+	// GetTaskID 与发送模板卡片消息时指定的task_id相同
+	GetTaskID() string
+
+	// GetCardType 通用模板卡片的类型
+	GetCardType() string
+
+	// GetResponseCode 用于调用更新卡片接口的ResponseCode，72小时内有效，且只能使用一次
+	GetResponseCode() string
+}
+
+var _ EventTemplateCardMenuEvent = (*rxEventTemplateCardMenuEvent)(nil)
+
+func (r *rxEventTemplateCardMenuEvent) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, TaskID: %#v, CardType: %#v, ResponseCode: %#v",
+		r.EventKey,
+		r.TaskID,
+		r.CardType,
+		r.ResponseCode,
+	)
+}
+
+func (r *rxEventTemplateCardMenuEvent) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventTemplateCardMenuEvent) GetTaskID() string {
+	return r.TaskID
+}
+
+func (r *rxEventTemplateCardMenuEvent) GetCardType() string {
+	return r.CardType
+}
+
+func (r *rxEventTemplateCardMenuEvent) GetResponseCode() string {
+	return r.ResponseCode
+}
+
+// EventTaskCardClick 任务卡片事件推送
+type EventTaskCardClick interface {
+	messageKind
+
+	// GetEventKey 与发送任务卡片消息时指定的按钮btn:key值相同
+	GetEventKey() string
+
+	// GetTaskID 与发送任务卡片消息时指定的task_id相同
+	GetTaskID() string
+}
+
+var _ EventTaskCardClick = (*rxEventTaskCardClick)(nil)
+
+func (r *rxEventTaskCardClick) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v, TaskID: %#v", r.EventKey, r.TaskID)
+}
+
+func (r *rxEventTaskCardClick) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventTaskCardClick) GetTaskID() string {
+	return r.TaskID
+}
+
+// EventEnterAgent 进入应用事件
+type EventEnterAgent interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，此事件该值为空
+	GetEventKey() string
+}
+
+var _ EventEnterAgent = (*rxEventEnterAgent)(nil)
+
+func (r *rxEventEnterAgent) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventEnterAgent) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventLocation 上报地理位置事件
+type EventLocation interface {
+	messageKind
+
+	// GetLatitude 地理位置纬度
+	GetLatitude() float64
+
+	// GetLongitude 地理位置经度
+	GetLongitude() float64
+
+	// GetPrecision 地理位置精度
+	GetPrecision() float64
+
+	// GetAppType app类型，在企业微信固定返回wxwork，在微信不返回该字段
+	GetAppType() string
+}
+
+var _ EventLocation = (*rxEventLocation)(nil)
+
+func (r *rxEventLocation) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"Latitude: %#v, Longitude: %#v, Precision: %#v, AppType: %#v",
+		r.Latitude,
+		r.Longitude,
+		r.Precision,
+		r.AppType,
+	)
+}
+
+func (r *rxEventLocation) GetLatitude() float64 {
+	return r.Latitude
+}
+
+func (r *rxEventLocation) GetLongitude() float64 {
+	return r.Longitude
+}
+
+func (r *rxEventLocation) GetPrecision() float64 {
+	return r.Precision
+}
+
+func (r *rxEventLocation) GetAppType() string {
+	return r.AppType
+}
+
+// EventBatchJobResult 异步任务完成通知
+type EventBatchJobResult interface {
+	messageKind
+
+	// GetJobID 异步任务id
+	GetJobID() string
+
+	// GetJobType 操作类型，如 sync_user、replace_user、invite_user、replace_party
+	GetJobType() string
+
+	// GetErrCode 返回码
+	GetErrCode() int
+
+	// GetErrMsg 对返回码的文本描述内容
+	GetErrMsg() string
+}
+
+var _ EventBatchJobResult = (*rxEventBatchJobResult)(nil)
+
+func (r *rxEventBatchJobResult) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"JobID: %#v, JobType: %#v, ErrCode: %d, ErrMsg: %#v",
+		r.JobID,
+		r.JobType,
+		r.ErrCode,
+		r.ErrMsg,
+	)
+}
+
+func (r *rxEventBatchJobResult) GetJobID() string {
+	return r.JobID
+}
+
+func (r *rxEventBatchJobResult) GetJobType() string {
+	return r.JobType
+}
+
+func (r *rxEventBatchJobResult) GetErrCode() int {
+	return r.ErrCode
+}
+
+func (r *rxEventBatchJobResult) GetErrMsg() string {
+	return r.ErrMsg
+}
+
+// EventOpenApprovalChange 审批状态通知事件，仅自建应用审批
+type EventOpenApprovalChange interface {
+	messageKind
+
+	// GetApprovalInfo 审批信息
+	GetApprovalInfo() OpenApprovalInfo
+}
+
+var _ EventOpenApprovalChange = (*rxEventOpenApprovalChange)(nil)
+
+func (r *rxEventOpenApprovalChange) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "ApprovalInfo: %#v", r.ApprovalInfo)
+}
+
+func (r *rxEventOpenApprovalChange) GetApprovalInfo() OpenApprovalInfo {
+	return r.ApprovalInfo
+}
+
+// EventKfMsgOrEvent 客服接收消息和事件
+type EventKfMsgOrEvent interface {
+	messageKind
+
+	// GetOpenKfID 客服账号ID
+	GetOpenKfID() string
+
+	// GetToken 调用拉取消息接口时，需要传此token，用于校验请求的合法性
+	GetToken() string
+}
+
+var _ EventKfMsgOrEvent = (*rxEventKfMsgOrEvent)(nil)
+
+func (r *rxEventKfMsgOrEvent) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"OpenKfID: %#v, Token: %#v",
+		r.OpenKfID,
+		r.Token,
+	)
+}
+
+func (r *rxEventKfMsgOrEvent) GetOpenKfID() string {
+	return r.OpenKfID
+}
+
+func (r *rxEventKfMsgOrEvent) GetToken() string {
+	return r.Token
+}
+
+// EventUnknown 未定义的事件类型，保留原始消息体以便自行解析
+type EventUnknown interface {
+	messageKind
+
+	// GetEventType 事件类型
+	GetEventType() string
+
+	// GetRaw 解密后的原始消息体
+	GetRaw() string
+}
+
+var _ EventUnknown = (*rxEventUnknown)(nil)
+
 func (r rxEventUnknown) formatInto(w io.Writer) {
 	_, _ = fmt.Fprintf(w, "Raw: %#v", r.Raw)
 }
+
+func (r *rxEventUnknown) GetEventType() string {
+	return r.EventType
+}
+
+func (r *rxEventUnknown) GetRaw() string {
+	return r.Raw
+}
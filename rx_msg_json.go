@@ -0,0 +1,87 @@
+package workwx
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// errRxMessageJSONNoRaw 序列化结果中缺少原始消息体
+var errRxMessageJSONNoRaw = errors.New("go-workwx: RxMessage JSON lacks raw payload")
+
+// Raw 返回解密后的原始 XML 消息体
+//
+// 未定义的消息、事件类型也可以据此自行解析。
+func (m *RxMessage) Raw() []byte {
+	return m.raw
+}
+
+// rxMessageJSON RxMessage 的 JSON 序列化格式
+//
+// 字段名一经发布即不再变动；Extras 的字段名沿用企业微信回调的 XML 元素名，
+// 仅供下游直接消费，反序列化时以 Raw 为准。
+type rxMessageJSON struct {
+	FromUserID   string          `json:"from_user_id"`
+	SendTime     int64           `json:"send_time"`
+	MsgType      MessageType     `json:"msg_type"`
+	MsgID        int64           `json:"msg_id,omitempty"`
+	AgentID      int64           `json:"agent_id,omitempty"`
+	Event        EventType       `json:"event,omitempty"`
+	ChangeType   ChangeType      `json:"change_type,omitempty"`
+	ToUserName   string          `json:"to_user_name,omitempty"`
+	CredentialID string          `json:"credential_id,omitempty"`
+	Extras       json.RawMessage `json:"extras,omitempty"`
+	Raw          string          `json:"raw"`
+}
+
+// MarshalJSON 将消息序列化为稳定的 JSON 格式，便于转发到消息队列或落库
+//
+// 结果包含解析后的各消息参数与原始消息体。处理上下文不会被序列化。
+func (m *RxMessage) MarshalJSON() ([]byte, error) {
+	var extras json.RawMessage
+	if m.extras != nil {
+		b, err := json.Marshal(m.extras)
+		if err != nil {
+			return nil, err
+		}
+		extras = b
+	}
+
+	return json.Marshal(rxMessageJSON{
+		FromUserID:   m.FromUserID,
+		SendTime:     m.SendTime.Unix(),
+		MsgType:      m.MsgType,
+		MsgID:        m.MsgID,
+		AgentID:      m.AgentID,
+		Event:        m.Event,
+		ChangeType:   m.ChangeType,
+		ToUserName:   m.toUserName,
+		CredentialID: m.credentialID,
+		Extras:       extras,
+		Raw:          string(m.raw),
+	})
+}
+
+// UnmarshalJSON 从 MarshalJSON 的结果重建消息
+//
+// 消息参数从原始消息体重新解析，因此与直接收到回调时完全一致。
+func (m *RxMessage) UnmarshalJSON(b []byte) error {
+	var x rxMessageJSON
+	err := json.Unmarshal(b, &x)
+	if err != nil {
+		return err
+	}
+
+	if x.Raw == "" {
+		return errRxMessageJSONNoRaw
+	}
+
+	msg, err := fromEnvelope([]byte(x.Raw))
+	if err != nil {
+		return err
+	}
+
+	msg.credentialID = x.CredentialID
+
+	*m = *msg
+	return nil
+}
@@ -0,0 +1,97 @@
+package workwx
+
+import (
+	"encoding/json"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxMessageJSON(t *testing.T) {
+	c.Convey("RxMessage 的 JSON 序列化", t, func() {
+		c.Convey("序列化后可以原样重建", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[zhangsan]]></FromUserName><CreateTime>1348831860</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[click]]></Event><EventKey><![CDATA[EVENTKEY]]></EventKey><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			msg.credentialID = "primary"
+			c.So(msg.Raw(), c.ShouldResemble, body)
+
+			b, err := json.Marshal(msg)
+			c.So(err, c.ShouldBeNil)
+
+			var fields map[string]any
+			c.So(json.Unmarshal(b, &fields), c.ShouldBeNil)
+			c.So(fields["from_user_id"], c.ShouldEqual, "zhangsan")
+			c.So(fields["send_time"], c.ShouldEqual, 1348831860)
+			c.So(fields["event"], c.ShouldEqual, "click")
+			c.So(fields["credential_id"], c.ShouldEqual, "primary")
+			c.So(fields["extras"], c.ShouldResemble, map[string]any{"EventKey": "EVENTKEY"})
+
+			var restored RxMessage
+			c.So(json.Unmarshal(b, &restored), c.ShouldBeNil)
+			c.So(restored.String(), c.ShouldEqual, msg.String())
+			c.So(restored.CredentialID(), c.ShouldEqual, "primary")
+			c.So(restored.SendTime.Equal(msg.SendTime), c.ShouldBeTrue)
+
+			y, ok := restored.EventAppMenuClick()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetEventKey(), c.ShouldEqual, "EVENTKEY")
+		})
+
+		c.Convey("extras 的字段名与企业微信的 XML 元素名一致", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1348831860</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_chat]]></Event><ChatId><![CDATA[CHAT_ID]]></ChatId><ChangeType><![CDATA[update]]></ChangeType><UpdateDetail><![CDATA[add_member]]></UpdateDetail><JoinScene>1</JoinScene><MemChangeCnt>2</MemChangeCnt><MemChangeList><Item>Jack</Item><Item>Rose</Item></MemChangeList></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			b, err := json.Marshal(msg)
+			c.So(err, c.ShouldBeNil)
+
+			var fields struct {
+				Extras map[string]any `json:"extras"`
+			}
+			c.So(json.Unmarshal(b, &fields), c.ShouldBeNil)
+			c.So(fields.Extras["ChatId"], c.ShouldEqual, "CHAT_ID")
+			c.So(fields.Extras["MemChangeList"], c.ShouldResemble, []any{"Jack", "Rose"})
+			c.So(fields.Extras, c.ShouldNotContainKey, "ChatID")
+		})
+
+		c.Convey("未定义的事件保留原始消息体", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1348831860</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[some_future_event]]></Event><Foo><![CDATA[bar]]></Foo></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventUnknown()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetEventType(), c.ShouldEqual, "some_future_event")
+			c.So(y.GetRaw(), c.ShouldEqual, string(body))
+
+			b, err := json.Marshal(msg)
+			c.So(err, c.ShouldBeNil)
+
+			var restored RxMessage
+			c.So(json.Unmarshal(b, &restored), c.ShouldBeNil)
+			c.So(restored.Raw(), c.ShouldResemble, body)
+		})
+
+		c.Convey("成员关注事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[UserID]]></FromUserName><CreateTime>1348831860</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe]]></Event><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			_, ok := msg.EventAppSubscribe()
+			c.So(ok, c.ShouldBeTrue)
+			_, ok = msg.EventAppUnsubscribe()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("缺少原始消息体时报错", func() {
+			var restored RxMessage
+			err := json.Unmarshal([]byte(`{"from_user_id":"zhangsan"}`), &restored)
+			c.So(err, c.ShouldEqual, errRxMessageJSONNoRaw)
+		})
+	})
+}
@@ -104,7 +104,17 @@ func TestRxMessageChangeContactUpdateUser(t *testing.T) {
 			{
 				y, ok := msg.EventChangeTypeUpdateUser()
 				c.So(ok, c.ShouldBeTrue)
-				c.So(y, c.ShouldNotBeNil)
+				c.So(y.GetUserID(), c.ShouldEqual, "zhangsan")
+				c.So(y.GetNewUserID(), c.ShouldEqual, "zhangsan001")
+				c.So(y.GetName(), c.ShouldEqual, "张三")
+				c.So(y.GetDepartment(), c.ShouldResemble, []int64{1, 2, 3})
+				c.So(y.GetIsLeaderInDept(), c.ShouldResemble, []bool{true, false, false})
+				c.So(y.GetGender(), c.ShouldEqual, 1)
+			}
+
+			{
+				_, ok := msg.EventChangeTypeCreateUser()
+				c.So(ok, c.ShouldBeFalse)
 			}
 		})
 	})
@@ -124,6 +134,268 @@ func TestRxMessageChangeContactCreateUser(t *testing.T) {
 				c.So(ok, c.ShouldBeTrue)
 				c.So(y, c.ShouldNotBeNil)
 			}
+
+			// 新增成员事件也满足删除成员事件的接口，不应被误认
+			{
+				y, ok := msg.EventChangeTypeDeleteUser()
+				c.So(ok, c.ShouldBeFalse)
+				c.So(y, c.ShouldBeNil)
+
+				_, ok = msg.EventChangeTypeUpdateUser()
+				c.So(ok, c.ShouldBeFalse)
+			}
+		})
+	})
+}
+
+func TestRxMessageChangeContactParty(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("删除成员事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>delete_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventChangeTypeDeleteUser()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetUserID(), c.ShouldEqual, "zhangsan")
+		})
+
+		c.Convey("新增部门事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>create_party</ChangeType><Id>2</Id><Name><![CDATA[张三]]></Name><ParentId><![CDATA[1]]></ParentId><Order>1</Order></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventChangeTypeCreateParty()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetID(), c.ShouldEqual, 2)
+			c.So(y.GetName(), c.ShouldEqual, "张三")
+			c.So(y.GetParentID(), c.ShouldEqual, 1)
+			c.So(y.GetOrder(), c.ShouldEqual, 1)
+
+			_, ok = msg.EventChangeTypeUpdateParty()
+			c.So(ok, c.ShouldBeFalse)
+			_, ok = msg.EventChangeTypeDeleteParty()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("更新部门事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>update_party</ChangeType><Id>2</Id><Name><![CDATA[张三]]></Name><ParentId><![CDATA[1]]></ParentId></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventChangeTypeUpdateParty()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetID(), c.ShouldEqual, 2)
+			c.So(y.GetParentID(), c.ShouldEqual, 1)
+
+			_, ok = msg.EventChangeTypeCreateParty()
+			c.So(ok, c.ShouldBeFalse)
+			_, ok = msg.EventChangeTypeDeleteParty()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("删除部门事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>delete_party</ChangeType><Id>2</Id></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventChangeTypeDeleteParty()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetID(), c.ShouldEqual, 2)
+		})
+
+		c.Convey("标签成员变更事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType><![CDATA[update_tag]]></ChangeType><TagId>1</TagId><AddUserItems><![CDATA[zhangsan,lisi]]></AddUserItems><DelUserItems><![CDATA[zhangsan1,lisi1]]></DelUserItems><AddPartyItems><![CDATA[1,2]]></AddPartyItems><DelPartyItems><![CDATA[3,4]]></DelPartyItems></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventChangeTypeUpdateTag()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetTagID(), c.ShouldEqual, 1)
+			c.So(y.GetAddUserItems(), c.ShouldResemble, []string{"zhangsan", "lisi"})
+			c.So(y.GetDelUserItems(), c.ShouldResemble, []string{"zhangsan1", "lisi1"})
+			c.So(y.GetAddPartyItems(), c.ShouldResemble, []int64{1, 2})
+			c.So(y.GetDelPartyItems(), c.ShouldResemble, []int64{3, 4})
+		})
+	})
+}
+
+func TestRxMessageAppMenuEvents(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("扫码推事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408090502</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[scancode_push]]></Event><EventKey><![CDATA[6]]></EventKey><ScanCodeInfo><ScanType><![CDATA[qrcode]]></ScanType><ScanResult><![CDATA[1]]></ScanResult></ScanCodeInfo><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventAppMenuScanCodePush()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetEventKey(), c.ShouldEqual, "6")
+			c.So(y.GetScanType(), c.ShouldEqual, "qrcode")
+			c.So(y.GetScanResult(), c.ShouldEqual, "1")
+
+			_, ok = msg.EventAppMenuScanCodeWaitMsg()
+			c.So(ok, c.ShouldBeFalse)
+			_, ok = msg.EventAppMenuViewMiniprogram()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("弹出拍照或者相册发图事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408090816</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[pic_photo_or_album]]></Event><EventKey><![CDATA[6]]></EventKey><SendPicsInfo><Count>1</Count><PicList><item><PicMd5Sum><![CDATA[5a75aaca956d97be686719218f275c6b]]></PicMd5Sum></item></PicList></SendPicsInfo><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventAppMenuPicPhotoOrAlbum()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetCount(), c.ShouldEqual, 1)
+			c.So(y.GetPicMd5Sums(), c.ShouldResemble, []string{"5a75aaca956d97be686719218f275c6b"})
+
+			_, ok = msg.EventAppMenuPicSysPhoto()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("弹出地理位置选择器事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408091189</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[location_select]]></Event><EventKey><![CDATA[6]]></EventKey><SendLocationInfo><Location_X><![CDATA[23]]></Location_X><Location_Y><![CDATA[113]]></Location_Y><Scale><![CDATA[15]]></Scale><Label><![CDATA[ 广州市海珠区客村艺苑路 106号]]></Label><Poiname><![CDATA[]]></Poiname></SendLocationInfo><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventAppMenuLocationSelect()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetLatitude(), c.ShouldEqual, 23)
+			c.So(y.GetLongitude(), c.ShouldEqual, 113)
+			c.So(y.GetScale(), c.ShouldEqual, 15)
+			c.So(y.GetLabel(), c.ShouldEqual, " 广州市海珠区客村艺苑路 106号")
+
+			// 该事件也满足位置消息的接口，不应被误认
+			l, ok := msg.Location()
+			c.So(ok, c.ShouldBeFalse)
+			c.So(l, c.ShouldBeNil)
+		})
+
+		c.Convey("点击菜单跳转小程序事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408091189</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[view_miniprogram]]></Event><EventKey><![CDATA[pages/index/index]]></EventKey><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventAppMenuViewMiniprogram()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetEventKey(), c.ShouldEqual, "pages/index/index")
+		})
+	})
+}
+
+func TestRxMessageAppLifecycleEvents(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("进入应用", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408091189</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[enter_agent]]></Event><EventKey><![CDATA[]]></EventKey><AgentID>1</AgentID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			_, ok := msg.EventEnterAgent()
+			c.So(ok, c.ShouldBeTrue)
+			_, ok = msg.EventAppMenuViewMiniprogram()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("上报地理位置", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[LOCATION]]></Event><Latitude>23.104</Latitude><Longitude>113.320</Longitude><Precision>65.000</Precision><AgentID>1</AgentID><AppType><![CDATA[wxwork]]></AppType></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventLocation()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetLatitude(), c.ShouldEqual, 23.104)
+			c.So(y.GetLongitude(), c.ShouldEqual, 113.320)
+			c.So(y.GetPrecision(), c.ShouldEqual, 65)
+			c.So(y.GetAppType(), c.ShouldEqual, "wxwork")
+		})
+
+		c.Convey("异步任务完成通知", func() {
+			body := []byte("<xml><ToUserName><![CDATA[wx28dbb14e3720FAKE]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1425284517</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[batch_job_result]]></Event><BatchJob><JobId><![CDATA[S0MrnndvRG5fadSlLwiBqiDDbM143UqTmKP3152FZk4]]></JobId><JobType><![CDATA[sync_user]]></JobType><ErrCode>0</ErrCode><ErrMsg><![CDATA[ok]]></ErrMsg></BatchJob></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventBatchJobResult()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetJobID(), c.ShouldEqual, "S0MrnndvRG5fadSlLwiBqiDDbM143UqTmKP3152FZk4")
+			c.So(y.GetJobType(), c.ShouldEqual, "sync_user")
+			c.So(y.GetErrCode(), c.ShouldEqual, 0)
+			c.So(y.GetErrMsg(), c.ShouldEqual, "ok")
+		})
+
+		c.Convey("自建应用审批状态通知", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1527838022</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[open_approval_change]]></Event><AgentID>1</AgentID><ApprovalInfo><ThirdNo><![CDATA[201806010001]]></ThirdNo><OpenSpName><![CDATA[付款]]></OpenSpName><OpenTemplateId><![CDATA[1234567890]]></OpenTemplateId><OpenSpStatus>1</OpenSpStatus><ApplyTime>1527837645</ApplyTime><ApplyUserName><![CDATA[xiaoming]]></ApplyUserName><ApplyUserId><![CDATA[1]]></ApplyUserId><ApplyUserParty><![CDATA[产品部]]></ApplyUserParty><ApplyUserImage><![CDATA[http://www.qq.com/xxx.png]]></ApplyUserImage><ApprovalNodes><ApprovalNode><NodeStatus>1</NodeStatus><NodeAttr>1</NodeAttr><NodeType>1</NodeType><Items><Item><ItemName><![CDATA[xiaohong]]></ItemName><ItemUserId><![CDATA[2]]></ItemUserId><ItemImage><![CDATA[http://www.qq.com/xxx.png]]></ItemImage><ItemStatus>1</ItemStatus><ItemSpeech><![CDATA[]]></ItemSpeech><ItemOpTime>0</ItemOpTime></Item></Items></ApprovalNode></ApprovalNodes><NotifyNodes><NotifyNode><ItemName><![CDATA[xiaogang]]></ItemName><ItemUserId><![CDATA[3]]></ItemUserId><ItemImage><![CDATA[http://www.qq.com/xxx.png]]></ItemImage></NotifyNode></NotifyNodes><approverstep>0</approverstep></ApprovalInfo></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			y, ok := msg.EventOpenApprovalChange()
+			c.So(ok, c.ShouldBeTrue)
+			info := y.GetApprovalInfo()
+			c.So(info.ThirdNo, c.ShouldEqual, "201806010001")
+			c.So(info.OpenSpStatus, c.ShouldEqual, 1)
+			c.So(info.ApprovalNodes, c.ShouldHaveLength, 1)
+			c.So(info.ApprovalNodes[0].Items[0].ItemUserID, c.ShouldEqual, "2")
+			c.So(info.NotifyNodes[0].ItemName, c.ShouldEqual, "xiaogang")
+		})
+	})
+}
+
+func TestRxMessageExternalChatAndTag(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("客户群成员入群事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_chat]]></Event><ChatId><![CDATA[CHAT_ID]]></ChatId><ChangeType><![CDATA[update]]></ChangeType><UpdateDetail><![CDATA[add_member]]></UpdateDetail><JoinScene>1</JoinScene><QuitScene>0</QuitScene><MemChangeCnt>2</MemChangeCnt><MemChangeList><Item>Jack</Item><Item>Rose</Item></MemChangeList><LastMemVer>9c3f97c2ada667dfb5f6d03308d963e1</LastMemVer><CurMemVer>71217227bbd112ecfe3a49c482195cb4</CurMemVer></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeUpdate)
+
+			y, ok := msg.EventChangeExternalChat()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetChatID(), c.ShouldEqual, "CHAT_ID")
+			c.So(y.GetUpdateDetail(), c.ShouldEqual, ExternalChatUpdateDetailAddMember)
+			c.So(y.GetJoinScene(), c.ShouldEqual, 1)
+			c.So(y.GetMemChangeCnt(), c.ShouldEqual, 2)
+			c.So(y.GetMemChangeList(), c.ShouldResemble, []string{"Jack", "Rose"})
+			c.So(y.GetLastMemVer(), c.ShouldEqual, "9c3f97c2ada667dfb5f6d03308d963e1")
+			c.So(y.GetCurMemVer(), c.ShouldEqual, "71217227bbd112ecfe3a49c482195cb4")
+		})
+
+		c.Convey("企业客户标签创建事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_tag]]></Event><Id><![CDATA[TAG_ID]]></Id><TagType><![CDATA[tag]]></TagType><ChangeType><![CDATA[create]]></ChangeType><StrategyId>1</StrategyId></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeCreate)
+
+			y, ok := msg.EventChangeExternalTag()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetID(), c.ShouldEqual, "TAG_ID")
+			c.So(y.GetTagType(), c.ShouldEqual, "tag")
+			c.So(y.GetStrategyID(), c.ShouldEqual, 1)
+		})
+
+		c.Convey("企业客户标签重排事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_tag]]></Event><Id><![CDATA[]]></Id><ChangeType><![CDATA[shuffle]]></ChangeType></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeShuffle)
+
+			y, ok := msg.EventChangeExternalTag()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(y.GetID(), c.ShouldEqual, "")
 		})
 	})
 }
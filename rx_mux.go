@@ -0,0 +1,330 @@
+package workwx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RxMiddleware 接收消息处理的中间件
+//
+// 实现时请使用 DispatchRxMessage 调用 next，并以 RxMessageHandlerFunc 包装返回值，
+// 以免丢失被动回复。
+type RxMiddleware func(next RxMessageHandler) RxMessageHandler
+
+// ChainRxMiddlewares 将中间件依次套在 h 外面
+//
+// 第一个中间件在最外层，即最先看到消息。
+func ChainRxMiddlewares(h RxMessageHandler, mws ...RxMiddleware) RxMessageHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// rxRouteKey 消息路由的键
+//
+// 空字符串的 Event、ChangeType 表示匹配任意值。
+type rxRouteKey struct {
+	MsgType    MessageType
+	Event      EventType
+	ChangeType ChangeType
+}
+
+// RxMux 按消息类型、事件类型与变更类型分派接收消息的 RxMessageHandler
+//
+// 匹配时优先选择最具体的路由：先按 MsgType、Event、ChangeType 三者精确匹配，
+// 再依次放宽 ChangeType、Event。都匹配不上时交给 Fallback 设置的处理函数，
+// 未设置则忽略该消息。
+//
+// RxMux 可以直接传给 NewHTTPHandler。如果匹配到的处理函数实现了 RxMessageReplier，
+// 其被动回复会被原样返回。
+type RxMux struct {
+	mu       sync.RWMutex
+	routes   map[rxRouteKey]RxMessageHandler
+	fallback RxMessageHandler
+}
+
+var _ RxMessageHandler = (*RxMux)(nil)
+var _ RxMessageReplier = (*RxMux)(nil)
+
+// NewRxMux 构造一个空的 RxMux
+func NewRxMux() *RxMux {
+	return &RxMux{
+		mu:       sync.RWMutex{},
+		routes:   make(map[rxRouteKey]RxMessageHandler),
+		fallback: nil,
+	}
+}
+
+// Handle 为给定的消息类型、事件类型与变更类型注册处理函数
+//
+// event、changeType 传空字符串表示匹配任意值。重复注册同一路由会覆盖先前的注册。
+func (m *RxMux) Handle(
+	msgType MessageType,
+	event EventType,
+	changeType ChangeType,
+	h RxMessageHandler,
+	mws ...RxMiddleware,
+) {
+	key := rxRouteKey{MsgType: msgType, Event: event, ChangeType: changeType}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.routes[key] = ChainRxMiddlewares(h, mws...)
+}
+
+// Fallback 设置没有任何路由匹配时的处理函数
+func (m *RxMux) Fallback(h RxMessageHandler, mws ...RxMiddleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fallback = ChainRxMiddlewares(h, mws...)
+}
+
+func (m *RxMux) match(msg *RxMessage) RxMessageHandler {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	candidates := []rxRouteKey{
+		{MsgType: msg.MsgType, Event: msg.Event, ChangeType: msg.ChangeType},
+		{MsgType: msg.MsgType, Event: msg.Event},
+		{MsgType: msg.MsgType},
+	}
+	for _, k := range candidates {
+		if h, ok := m.routes[k]; ok {
+			return h
+		}
+	}
+
+	return m.fallback
+}
+
+// OnIncomingMessage 一条消息到来时的回调。
+func (m *RxMux) OnIncomingMessage(msg *RxMessage) error {
+	_, err := m.OnIncomingMessageWithReply(msg)
+	return err
+}
+
+// OnIncomingMessageWithReply 一条消息到来时的回调。
+func (m *RxMux) OnIncomingMessageWithReply(msg *RxMessage) (RxReply, error) {
+	h := m.match(msg)
+	if h == nil {
+		return nil, nil
+	}
+
+	return DispatchRxMessage(h, msg)
+}
+
+// rxTypedHandler 将带类型消息参数的处理函数适配为 RxMessageHandler
+func rxTypedHandler[T any](
+	h func(ctx context.Context, msg *RxMessage, extras T) error,
+	extract func(msg *RxMessage) (T, bool),
+) RxMessageHandler {
+	return RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+		extras, ok := extract(msg)
+		if !ok {
+			// 路由与消息参数类型不一致，只可能是 SDK 自身的问题
+			return nil, fmt.Errorf(
+				"go-workwx: message extras mismatch for msgtype=%s event=%s changetype=%s",
+				msg.MsgType,
+				msg.Event,
+				msg.ChangeType,
+			)
+		}
+
+		return nil, h(msg.Context(), msg, extras)
+	})
+}
+
+// OnText 注册文本消息的处理函数
+func (m *RxMux) OnText(
+	h func(ctx context.Context, msg *RxMessage, extras TextMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeText, "", "", rxTypedHandler(h, (*RxMessage).Text), mws...)
+}
+
+// OnImage 注册图片消息的处理函数
+func (m *RxMux) OnImage(
+	h func(ctx context.Context, msg *RxMessage, extras ImageMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeImage, "", "", rxTypedHandler(h, (*RxMessage).Image), mws...)
+}
+
+// OnVoice 注册语音消息的处理函数
+func (m *RxMux) OnVoice(
+	h func(ctx context.Context, msg *RxMessage, extras VoiceMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeVoice, "", "", rxTypedHandler(h, (*RxMessage).Voice), mws...)
+}
+
+// OnVideo 注册视频消息的处理函数
+func (m *RxMux) OnVideo(
+	h func(ctx context.Context, msg *RxMessage, extras VideoMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeVideo, "", "", rxTypedHandler(h, (*RxMessage).Video), mws...)
+}
+
+// OnLocation 注册位置消息的处理函数
+func (m *RxMux) OnLocation(
+	h func(ctx context.Context, msg *RxMessage, extras LocationMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeLocation, "", "", rxTypedHandler(h, (*RxMessage).Location), mws...)
+}
+
+// OnLink 注册链接消息的处理函数
+func (m *RxMux) OnLink(
+	h func(ctx context.Context, msg *RxMessage, extras LinkMessageExtras) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(MessageTypeLink, "", "", rxTypedHandler(h, (*RxMessage).Link), mws...)
+}
+
+// OnExternalContactAdd 注册添加企业客户事件的处理函数
+func (m *RxMux) OnExternalContactAdd(
+	h func(ctx context.Context, msg *RxMessage, extras EventAddExternalContact) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeAddExternalContact,
+		rxTypedHandler(h, (*RxMessage).EventAddExternalContact),
+		mws...,
+	)
+}
+
+// OnExternalContactEdit 注册编辑企业客户事件的处理函数
+func (m *RxMux) OnExternalContactEdit(
+	h func(ctx context.Context, msg *RxMessage, extras EventEditExternalContact) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeEditExternalContact,
+		rxTypedHandler(h, (*RxMessage).EventEditExternalContact),
+		mws...,
+	)
+}
+
+// OnExternalContactAddHalf 注册外部联系人免验证添加成员事件的处理函数
+func (m *RxMux) OnExternalContactAddHalf(
+	h func(ctx context.Context, msg *RxMessage, extras EventAddHalfExternalContact) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeAddHalfExternalContact,
+		rxTypedHandler(h, (*RxMessage).EventAddHalfExternalContact),
+		mws...,
+	)
+}
+
+// OnExternalContactDel 注册删除企业客户事件的处理函数
+func (m *RxMux) OnExternalContactDel(
+	h func(ctx context.Context, msg *RxMessage, extras EventDelExternalContact) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeDelExternalContact,
+		rxTypedHandler(h, (*RxMessage).EventDelExternalContact),
+		mws...,
+	)
+}
+
+// OnExternalContactDelFollowUser 注册删除跟进成员事件的处理函数
+func (m *RxMux) OnExternalContactDelFollowUser(
+	h func(ctx context.Context, msg *RxMessage, extras EventDelFollowUser) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeDelFollowUser,
+		rxTypedHandler(h, (*RxMessage).EventDelFollowUser),
+		mws...,
+	)
+}
+
+// OnExternalContactTransferFail 注册客户接替失败事件的处理函数
+func (m *RxMux) OnExternalContactTransferFail(
+	h func(ctx context.Context, msg *RxMessage, extras EventTransferFail) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalContact,
+		ChangeTypeTransferFail,
+		rxTypedHandler(h, (*RxMessage).EventTransferFail),
+		mws...,
+	)
+}
+
+// OnExternalChatChange 注册客户群变更事件的处理函数
+func (m *RxMux) OnExternalChatChange(
+	h func(ctx context.Context, msg *RxMessage, extras EventChangeExternalChat) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalChat,
+		"",
+		rxTypedHandler(h, (*RxMessage).EventChangeExternalChat),
+		mws...,
+	)
+}
+
+// OnExternalTagChange 注册企业客户标签变更事件的处理函数
+func (m *RxMux) OnExternalTagChange(
+	h func(ctx context.Context, msg *RxMessage, extras EventChangeExternalTag) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeChangeExternalTag,
+		"",
+		rxTypedHandler(h, (*RxMessage).EventChangeExternalTag),
+		mws...,
+	)
+}
+
+// OnApprovalChange 注册审批申请状态变化回调通知的处理函数
+func (m *RxMux) OnApprovalChange(
+	h func(ctx context.Context, msg *RxMessage, extras EventSysApprovalChange) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeSysApprovalChange,
+		"",
+		rxTypedHandler(h, (*RxMessage).EventSysApprovalChange),
+		mws...,
+	)
+}
+
+// OnKfMsgOrEvent 注册客服接收消息和事件的处理函数
+func (m *RxMux) OnKfMsgOrEvent(
+	h func(ctx context.Context, msg *RxMessage, extras EventKfMsgOrEvent) error,
+	mws ...RxMiddleware,
+) {
+	m.Handle(
+		MessageTypeEvent,
+		EventTypeKfMsgOrEvent,
+		"",
+		rxTypedHandler(h, func(msg *RxMessage) (EventKfMsgOrEvent, bool) {
+			return msg.EventKfMsgOrEvent()
+		}),
+		mws...,
+	)
+}
@@ -0,0 +1,74 @@
+package workwx
+
+import (
+	"context"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxMux(t *testing.T) {
+	c.Convey("按消息类型分派", t, func() {
+		textBody := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+		editBody := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName> <CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_contact]]></Event><ChangeType><![CDATA[edit_external_contact]]></ChangeType><UserID><![CDATA[zhangsan]]></UserID><ExternalUserID><![CDATA[woAJ2GCAAAXtWyujaWJHDDGi0mAAAA]]></ExternalUserID><State><![CDATA[teststate]]></State></xml>")
+
+		textMsg, err := fromEnvelope(textBody)
+		c.So(err, c.ShouldBeNil)
+		editMsg, err := fromEnvelope(editBody)
+		c.So(err, c.ShouldBeNil)
+
+		mux := NewRxMux()
+		var trace []string
+
+		mux.OnText(func(_ context.Context, _ *RxMessage, extras TextMessageExtras) error {
+			trace = append(trace, "text:"+extras.GetContent())
+			return nil
+		}, func(next RxMessageHandler) RxMessageHandler {
+			return RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+				trace = append(trace, "mw")
+				return DispatchRxMessage(next, msg)
+			})
+		})
+
+		c.Convey("带中间件的类型化路由", func() {
+			c.So(mux.OnIncomingMessage(textMsg), c.ShouldBeNil)
+			c.So(trace, c.ShouldResemble, []string{"mw", "text:x123"})
+		})
+
+		c.Convey("没有匹配也没有 fallback 时忽略", func() {
+			c.So(mux.OnIncomingMessage(editMsg), c.ShouldBeNil)
+			c.So(trace, c.ShouldBeEmpty)
+		})
+
+		c.Convey("fallback", func() {
+			mux.Fallback(RxMessageHandlerFunc(func(msg *RxMessage) (RxReply, error) {
+				trace = append(trace, "fallback:"+string(msg.ChangeType))
+				return nil, nil
+			}))
+			c.So(mux.OnIncomingMessage(editMsg), c.ShouldBeNil)
+			c.So(trace, c.ShouldResemble, []string{"fallback:edit_external_contact"})
+		})
+
+		c.Convey("更具体的路由优先", func() {
+			mux.Handle(MessageTypeEvent, EventTypeChangeExternalContact, "", RxMessageHandlerFunc(func(*RxMessage) (RxReply, error) {
+				trace = append(trace, "any change_external_contact")
+				return nil, nil
+			}))
+			mux.OnExternalContactEdit(func(_ context.Context, _ *RxMessage, extras EventEditExternalContact) error {
+				trace = append(trace, "edit:"+extras.GetState())
+				return nil
+			})
+			c.So(mux.OnIncomingMessage(editMsg), c.ShouldBeNil)
+			c.So(trace, c.ShouldResemble, []string{"edit:teststate"})
+		})
+
+		c.Convey("被动回复原样返回", func() {
+			mux.Handle(MessageTypeText, "", "", RxMessageHandlerFunc(func(*RxMessage) (RxReply, error) {
+				return &TextReply{Content: "pong"}, nil
+			}))
+			reply, err := mux.OnIncomingMessageWithReply(textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldResemble, &TextReply{Content: "pong"})
+		})
+	})
+}
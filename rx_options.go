@@ -0,0 +1,287 @@
+package workwx
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/httpapi"
+)
+
+type httpHandlerOptions struct {
+	DedupStore RxDedupStore
+	DedupTTL   time.Duration
+	Async      *AsyncConfig
+	Replay     *ReplayProtection
+
+	Middlewares []RxMiddleware
+	ErrorPolicy RxErrorPolicy
+	ErrorHook   func(msg *RxMessage, err error)
+
+	MaxBodySize       int64
+	SourceFilter      func(r *http.Request) bool
+	ExpectedReceiveID string
+	RejectHook        func(r *http.Request, err error)
+}
+
+// HTTPHandlerOption 回调 HTTP handler 构造参数
+type HTTPHandlerOption interface {
+	applyTo(*httpHandlerOptions)
+}
+
+// impl Default for httpHandlerOptions
+func defaultHTTPHandlerOptions() httpHandlerOptions {
+	return httpHandlerOptions{
+		DedupStore: nil,
+		DedupTTL:   0,
+		Async:      nil,
+		Replay:     nil,
+
+		Middlewares: nil,
+		ErrorPolicy: nil,
+		ErrorHook:   nil,
+
+		MaxBodySize:       DefaultMaxCallbackBodySize,
+		SourceFilter:      nil,
+		ExpectedReceiveID: "",
+		RejectHook:        nil,
+	}
+}
+
+// processorOptions 回调包解密相关的参数
+func (o *httpHandlerOptions) processorOptions() []envelope.ProcessorOption {
+	if o.Replay == nil {
+		return nil
+	}
+
+	return o.Replay.intoProcessorOptions()
+}
+
+// applyToLowlevel 设置请求校验相关的参数
+func (o *httpHandlerOptions) applyToLowlevel(h *httpapi.LowlevelHandler) {
+	h.SetMaxBodySize(o.MaxBodySize)
+	h.SetSourceFilter(o.SourceFilter)
+	h.SetExpectedReceiveID(o.ExpectedReceiveID)
+	h.SetRejectHook(o.RejectHook)
+}
+
+//
+//
+//
+
+type withDedup struct {
+	store RxDedupStore
+	ttl   time.Duration
+}
+
+// WithDedup 使用给定的存储对企业微信的重试回调去重
+//
+// 企业微信在 5 秒内未收到响应时会重新推送同一回调；开启去重后，在 ttl 时间内
+// 重复收到的同一消息或事件不会再次交给 RxMessageHandler 处理。
+// ttl 为零时使用 DefaultDedupTTL。
+//
+// 使用被动回复时，store 须实现 RxDedupReplyStore，重试推送才能收到同样的回复。
+func WithDedup(store RxDedupStore, ttl time.Duration) HTTPHandlerOption {
+	return &withDedup{store: store, ttl: ttl}
+}
+
+var _ HTTPHandlerOption = (*withDedup)(nil)
+
+func (x *withDedup) applyTo(y *httpHandlerOptions) {
+	y.DedupStore = x.store
+	y.DedupTTL = x.ttl
+	if y.DedupTTL == 0 {
+		y.DedupTTL = DefaultDedupTTL
+	}
+}
+
+//
+//
+//
+
+type withAsync struct {
+	x AsyncConfig
+}
+
+// WithAsync 开启回调的异步处理，详见 AsyncConfig
+//
+// 开启后请在退出前调用 HTTPHandler.Shutdown 以处理完队列中剩余的消息。
+func WithAsync(cfg AsyncConfig) HTTPHandlerOption {
+	return &withAsync{x: cfg}
+}
+
+var _ HTTPHandlerOption = (*withAsync)(nil)
+
+func (x *withAsync) applyTo(y *httpHandlerOptions) {
+	cfg := x.x
+	y.Async = &cfg
+}
+
+//
+//
+//
+
+type withReplayProtection struct {
+	x ReplayProtection
+}
+
+// WithReplayProtection 开启回调防重放，详见 ReplayProtection
+func WithReplayProtection(cfg ReplayProtection) HTTPHandlerOption {
+	return &withReplayProtection{x: cfg}
+}
+
+var _ HTTPHandlerOption = (*withReplayProtection)(nil)
+
+func (x *withReplayProtection) applyTo(y *httpHandlerOptions) {
+	cfg := x.x
+	y.Replay = &cfg
+}
+
+//
+//
+//
+
+type withMiddlewares struct {
+	x []RxMiddleware
+}
+
+// WithMiddlewares 在 RxMessageHandler 外套上给定的中间件，顺序同 ChainRxMiddlewares
+func WithMiddlewares(mws ...RxMiddleware) HTTPHandlerOption {
+	return &withMiddlewares{x: mws}
+}
+
+var _ HTTPHandlerOption = (*withMiddlewares)(nil)
+
+func (x *withMiddlewares) applyTo(y *httpHandlerOptions) {
+	y.Middlewares = append(y.Middlewares, x.x...)
+}
+
+//
+//
+//
+
+type withErrorPolicy struct {
+	x RxErrorPolicy
+}
+
+// WithErrorPolicy 按给定策略决定消息处理失败时的响应方式
+//
+// 默认总是以 500 响应，由企业微信重试（RxErrorRetry）。
+// 异步模式下消息在处理前就已经响应，策略只对解析、入队失败生效。
+func WithErrorPolicy(policy RxErrorPolicy) HTTPHandlerOption {
+	return &withErrorPolicy{x: policy}
+}
+
+var _ HTTPHandlerOption = (*withErrorPolicy)(nil)
+
+func (x *withErrorPolicy) applyTo(y *httpHandlerOptions) {
+	y.ErrorPolicy = x.x
+}
+
+//
+//
+//
+
+type withErrorHook struct {
+	x func(msg *RxMessage, err error)
+}
+
+// WithErrorHook 在消息解析或处理失败时调用 hook，可用于记录日志或报警
+//
+// 消息解析失败时 msg 为 nil；处理函数中的 panic 以 *RxPanicError 报告。
+// 异步模式下的处理失败同样会报告给 hook，且不再报告给 AsyncConfig.OnError。
+func WithErrorHook(hook func(msg *RxMessage, err error)) HTTPHandlerOption {
+	return &withErrorHook{x: hook}
+}
+
+var _ HTTPHandlerOption = (*withErrorHook)(nil)
+
+func (x *withErrorHook) applyTo(y *httpHandlerOptions) {
+	y.ErrorHook = x.x
+}
+
+//
+//
+//
+
+type withMaxBodySize struct {
+	x int64
+}
+
+// WithMaxBodySize 限制回调请求体的大小，超出的请求以 413 拒绝
+//
+// 默认为 DefaultMaxCallbackBodySize；非正数表示不限制。
+func WithMaxBodySize(n int64) HTTPHandlerOption {
+	return &withMaxBodySize{x: n}
+}
+
+var _ HTTPHandlerOption = (*withMaxBodySize)(nil)
+
+func (x *withMaxBodySize) applyTo(y *httpHandlerOptions) {
+	y.MaxBodySize = x.x
+}
+
+//
+//
+//
+
+type withSourceFilter struct {
+	x func(r *http.Request) bool
+}
+
+// WithSourceFilter 只接受 allow 返回 true 的回调请求，其余以 403 拒绝
+//
+// 可以使用 AllowCallbackSourceIPs 按企业微信公布的回调 IP 段过滤。
+func WithSourceFilter(allow func(r *http.Request) bool) HTTPHandlerOption {
+	return &withSourceFilter{x: allow}
+}
+
+var _ HTTPHandlerOption = (*withSourceFilter)(nil)
+
+func (x *withSourceFilter) applyTo(y *httpHandlerOptions) {
+	y.SourceFilter = x.x
+}
+
+//
+//
+//
+
+type withExpectedReceiveID struct {
+	x string
+}
+
+// WithExpectedReceiveID 要求回调包解密所得的 ReceiveID 与给定值一致，一般即 CorpID
+//
+// 不一致的请求以 400 拒绝，错误为 ErrCallbackReceiveIDMismatch。
+func WithExpectedReceiveID(id string) HTTPHandlerOption {
+	return &withExpectedReceiveID{x: id}
+}
+
+var _ HTTPHandlerOption = (*withExpectedReceiveID)(nil)
+
+func (x *withExpectedReceiveID) applyTo(y *httpHandlerOptions) {
+	y.ExpectedReceiveID = x.x
+}
+
+//
+//
+//
+
+type withRejectHook struct {
+	x func(r *http.Request, err error)
+}
+
+// WithRejectHook 在回调请求被拒绝时调用 hook，可用于记录日志或报警
+//
+// err 可用 errors.Is 与 ErrCallbackBodyTooLarge、ErrCallbackSourceNotAllowed、
+// ErrCallbackMalformed、ErrCallbackInvalidSignature、ErrCallbackDecryptFailed、
+// ErrCallbackReceiveIDMismatch、ErrCallbackReplayed 比较。
+func WithRejectHook(hook func(r *http.Request, err error)) HTTPHandlerOption {
+	return &withRejectHook{x: hook}
+}
+
+var _ HTTPHandlerOption = (*withRejectHook)(nil)
+
+func (x *withRejectHook) applyTo(y *httpHandlerOptions) {
+	y.RejectHook = x.x
+}
@@ -0,0 +1,54 @@
+package workwx
+
+import (
+	"time"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+)
+
+// DefaultReplayWindow 默认的回调时间戳有效窗口
+const DefaultReplayWindow = 5 * time.Minute
+
+// ErrCallbackReplayed 回调请求疑似重放
+//
+// 时间戳超出有效窗口或 nonce 重复的请求会以此错误（的包装）被拒绝，可用
+// errors.Is 判断。
+var ErrCallbackReplayed = envelope.ErrReplayDetected
+
+// ReplayProtection 回调防重放配置
+//
+// 回调请求的签名并不覆盖请求何时发出，截获的请求可以被无限重放。开启防重放后，
+// timestamp 参数与当前时间相差超过 Window 的请求会被拒绝；如果还设置了
+// NonceCache，窗口内重复出现的 nonce 也会被拒绝。
+type ReplayProtection struct {
+	// Window timestamp 与当前时间允许的最大偏差，默认为 DefaultReplayWindow
+	Window time.Duration
+	// NonceCache 记录窗口内见过的 nonce，为 nil 则只检查时间戳
+	//
+	// 可以使用 NewMemoryDedupStore；多实例部署时需要共享的实现。
+	NonceCache RxDedupStore
+	// OnReject 请求因疑似重放被拒绝时的回调，可用于上报监控指标，可以为 nil
+	OnReject func(err error)
+}
+
+func (x *ReplayProtection) intoProcessorOptions() []envelope.ProcessorOption {
+	window := x.Window
+	if window <= 0 {
+		window = DefaultReplayWindow
+	}
+
+	result := []envelope.ProcessorOption{envelope.WithReplayWindow(window)}
+	if x.NonceCache != nil {
+		result = append(result, envelope.WithNonceCache(x.NonceCache))
+	}
+	if x.OnReject != nil {
+		result = append(result, envelope.WithReplayRejectHook(x.OnReject))
+	}
+
+	return result
+}
+
+// ReplayRejectedCount 返回因疑似重放而被拒绝的回调请求总数
+func (h *HTTPHandler) ReplayRejectedCount() uint64 {
+	return h.inner.ReplayRejectedCount()
+}
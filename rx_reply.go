@@ -0,0 +1,170 @@
+package workwx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrInvalidReply 被动回复的消息不合法，如图文消息的条数不在 1~8 之间
+var ErrInvalidReply = errors.New("go-workwx: invalid passive reply")
+
+// maxNewsReplyArticles 被动回复图文消息的最大条数
+const maxNewsReplyArticles = 8
+
+// RxReply 被动回复的消息
+//
+// 仅可使用本包提供的 TextReply、ImageReply、NewsReply、UpdateButtonReply 等类型。
+//
+// NOTE: 这顺便就构成了一个封闭的 enum
+type RxReply interface {
+	intoReplyXML(common txReplyCommon) any
+}
+
+// TextReply 被动回复文本消息
+type TextReply struct {
+	// Content 文本消息内容
+	Content string
+}
+
+// ImageReply 被动回复图片消息
+type ImageReply struct {
+	// MediaID 图片媒体文件 ID，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+// NewsReply 被动回复图文消息
+type NewsReply struct {
+	// Articles 图文消息列表，1~8 条
+	//
+	// 仅使用 Title、Description、URL、PicURL 字段。
+	Articles []Article
+}
+
+// UpdateButtonReply 被动回复更新模板卡片按钮
+//
+// 仅用于响应模板卡片的按钮点击事件，将被点击卡片的按钮更新为不可点击状态。
+type UpdateButtonReply struct {
+	// ReplaceName 点击后按钮上显示的文案
+	ReplaceName string
+}
+
+var _ RxReply = (*TextReply)(nil)
+var _ RxReply = (*ImageReply)(nil)
+var _ RxReply = (*NewsReply)(nil)
+var _ RxReply = (*UpdateButtonReply)(nil)
+
+type xmlCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// txReplyCommon 被动回复消息的公共部分
+type txReplyCommon struct {
+	ToUserName   xmlCDATA `xml:"ToUserName"`
+	FromUserName xmlCDATA `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      xmlCDATA `xml:"MsgType"`
+}
+
+type txTextReply struct {
+	XMLName xml.Name `xml:"xml"`
+	txReplyCommon
+
+	Content xmlCDATA `xml:"Content"`
+}
+
+func (r *TextReply) intoReplyXML(common txReplyCommon) any {
+	common.MsgType = xmlCDATA{"text"}
+	return &txTextReply{
+		txReplyCommon: common,
+		Content:       xmlCDATA{r.Content},
+	}
+}
+
+type txImageReply struct {
+	XMLName xml.Name `xml:"xml"`
+	txReplyCommon
+
+	MediaID xmlCDATA `xml:"Image>MediaId"`
+}
+
+func (r *ImageReply) intoReplyXML(common txReplyCommon) any {
+	common.MsgType = xmlCDATA{"image"}
+	return &txImageReply{
+		txReplyCommon: common,
+		MediaID:       xmlCDATA{r.MediaID},
+	}
+}
+
+type txNewsReplyArticle struct {
+	Title       xmlCDATA `xml:"Title"`
+	Description xmlCDATA `xml:"Description"`
+	PicURL      xmlCDATA `xml:"PicUrl"`
+	URL         xmlCDATA `xml:"Url"`
+}
+
+type txNewsReply struct {
+	XMLName xml.Name `xml:"xml"`
+	txReplyCommon
+
+	ArticleCount int                  `xml:"ArticleCount"`
+	Articles     []txNewsReplyArticle `xml:"Articles>item"`
+}
+
+func (r *NewsReply) intoReplyXML(common txReplyCommon) any {
+	common.MsgType = xmlCDATA{"news"}
+
+	articles := make([]txNewsReplyArticle, len(r.Articles))
+	for i, a := range r.Articles {
+		articles[i] = txNewsReplyArticle{
+			Title:       xmlCDATA{a.Title},
+			Description: xmlCDATA{a.Description},
+			PicURL:      xmlCDATA{a.PicURL},
+			URL:         xmlCDATA{a.URL},
+		}
+	}
+
+	return &txNewsReply{
+		txReplyCommon: common,
+		ArticleCount:  len(articles),
+		Articles:      articles,
+	}
+}
+
+type txUpdateButtonReply struct {
+	XMLName xml.Name `xml:"xml"`
+	txReplyCommon
+
+	ReplaceName xmlCDATA `xml:"Button>ReplaceName"`
+}
+
+func (r *UpdateButtonReply) intoReplyXML(common txReplyCommon) any {
+	common.MsgType = xmlCDATA{"update_button"}
+	return &txUpdateButtonReply{
+		txReplyCommon: common,
+		ReplaceName:   xmlCDATA{r.ReplaceName},
+	}
+}
+
+// marshalRxReply 组装对某条接收消息的被动回复明文 XML
+func marshalRxReply(msg *RxMessage, reply RxReply, now time.Time) ([]byte, error) {
+	// 类型化的 nil 指针不等于 nil 接口，在这里拦下以免 intoReplyXML 空指针 panic
+	if v := reflect.ValueOf(reply); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, fmt.Errorf("%w: nil %T", ErrInvalidReply, reply)
+	}
+	if x, ok := reply.(*NewsReply); ok {
+		if n := len(x.Articles); n == 0 || n > maxNewsReplyArticles {
+			return nil, fmt.Errorf("%w: news reply must have 1 to %d articles, got %d", ErrInvalidReply, maxNewsReplyArticles, n)
+		}
+	}
+
+	common := txReplyCommon{
+		ToUserName:   xmlCDATA{msg.FromUserID},
+		FromUserName: xmlCDATA{msg.toUserName},
+		CreateTime:   now.Unix(),
+	}
+
+	return xml.Marshal(reply.intoReplyXML(common))
+}
@@ -0,0 +1,67 @@
+package workwx
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMarshalRxReply(t *testing.T) {
+	c.Convey("组装被动回复消息", t, func() {
+		body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+		msg, err := fromEnvelope(body)
+		c.So(err, c.ShouldBeNil)
+
+		now := time.Unix(1583995630, 0)
+
+		c.Convey("文本消息", func() {
+			out, err := marshalRxReply(msg, &TextReply{Content: "pong"}, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995630</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[pong]]></Content></xml>")
+		})
+
+		c.Convey("图片消息", func() {
+			out, err := marshalRxReply(msg, &ImageReply{MediaID: "MEDIA_ID"}, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995630</CreateTime><MsgType><![CDATA[image]]></MsgType><Image><MediaId><![CDATA[MEDIA_ID]]></MediaId></Image></xml>")
+		})
+
+		c.Convey("图文消息", func() {
+			reply := NewsReply{
+				Articles: []Article{
+					{Title: "t", Description: "d", URL: "https://example.com", PicURL: "https://example.com/a.png"},
+				},
+			}
+			out, err := marshalRxReply(msg, &reply, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995630</CreateTime><MsgType><![CDATA[news]]></MsgType><ArticleCount>1</ArticleCount><Articles><item><Title><![CDATA[t]]></Title><Description><![CDATA[d]]></Description><PicUrl><![CDATA[https://example.com/a.png]]></PicUrl><Url><![CDATA[https://example.com]]></Url></item></Articles></xml>")
+		})
+
+		c.Convey("图文消息的条数须在 1~8 之间", func() {
+			_, err := marshalRxReply(msg, &NewsReply{}, now)
+			c.So(errors.Is(err, ErrInvalidReply), c.ShouldBeTrue)
+
+			_, err = marshalRxReply(msg, &NewsReply{Articles: make([]Article, 8)}, now)
+			c.So(err, c.ShouldBeNil)
+
+			_, err = marshalRxReply(msg, &NewsReply{Articles: make([]Article, 9)}, now)
+			c.So(errors.Is(err, ErrInvalidReply), c.ShouldBeTrue)
+		})
+
+		c.Convey("类型化的 nil 指针", func() {
+			for _, reply := range []RxReply{(*TextReply)(nil), (*ImageReply)(nil), (*NewsReply)(nil), (*UpdateButtonReply)(nil)} {
+				var err error
+				c.So(func() { _, err = marshalRxReply(msg, reply, now) }, c.ShouldNotPanic)
+				c.So(errors.Is(err, ErrInvalidReply), c.ShouldBeTrue)
+			}
+		})
+
+		c.Convey("更新模板卡片按钮", func() {
+			out, err := marshalRxReply(msg, &UpdateButtonReply{ReplaceName: "已同意"}, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995630</CreateTime><MsgType><![CDATA[update_button]]></MsgType><Button><ReplaceName><![CDATA[已同意]]></ReplaceName></Button></xml>")
+		})
+	})
+}
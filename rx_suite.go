@@ -0,0 +1,464 @@
+package workwx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/httpapi"
+)
+
+// RxSuiteInfo 一条接收到的第三方应用指令回调
+type RxSuiteInfo struct {
+	// SuiteID 第三方应用的 SuiteID
+	SuiteID string
+	// InfoType 指令类型
+	InfoType SuiteInfoType
+	// Time 指令发出的时间
+	Time time.Time
+	// AuthCorpID 授权方的 CorpID，suite_ticket 指令为空
+	AuthCorpID string
+	// ChangeType 变更类型，InfoType 为 change_contact 时存在
+	ChangeType ChangeType
+
+	// ctx 处理本指令的上下文，即回调 HTTP 请求的上下文
+	ctx context.Context
+	// raw 解密后的原始消息体
+	raw []byte
+
+	extras messageKind
+}
+
+func fromSuiteEnvelope(body []byte) (*RxSuiteInfo, error) {
+	var common rxSuiteInfoCommon
+	err := xml.Unmarshal(body, &common)
+	if err != nil {
+		return nil, err
+	}
+	// 官方文档的示例中 CDATA 之前带有空白
+	common.InfoType = SuiteInfoType(strings.TrimSpace(string(common.InfoType)))
+
+	extras, err := extractSuiteInfoExtras(common, body)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := RxSuiteInfo{
+		SuiteID:    common.SuiteID,
+		InfoType:   common.InfoType,
+		Time:       time.Unix(common.TimeStamp, 0),
+		AuthCorpID: common.AuthCorpID,
+		ChangeType: common.ChangeType,
+
+		ctx: nil,
+		raw: body,
+
+		extras: extras,
+	}
+
+	return &obj, nil
+}
+
+func extractSuiteInfoExtras(common rxSuiteInfoCommon, body []byte) (messageKind, error) {
+	switch common.InfoType {
+	case SuiteInfoTypeSuiteTicket:
+		var x rxSuiteInfoSuiteTicket
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+
+	case SuiteInfoTypeCreateAuth:
+		var x rxSuiteInfoCreateAuth
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+
+	case SuiteInfoTypeChangeAuth:
+		var x rxSuiteInfoChangeAuth
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+
+	case SuiteInfoTypeCancelAuth:
+		return &rxSuiteInfoCancelAuth{}, nil
+
+	case SuiteInfoTypeResetPermanentCode:
+		var x rxSuiteInfoResetPermanentCode
+		err := xml.Unmarshal(body, &x)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+
+	case SuiteInfoTypeChangeContact:
+		return extractChangeContactExtras(common.ChangeType, body)
+
+	default:
+		// 返回一个未定义的指令类型
+		return &rxEventUnknown{EventType: string(common.InfoType), Raw: string(body)}, nil
+	}
+}
+
+// Context 返回处理本指令的上下文，始终不为 nil
+func (m *RxSuiteInfo) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+
+	return context.Background()
+}
+
+func (m *RxSuiteInfo) String() string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(
+		&sb,
+		"RxSuiteInfo { SuiteID: %#v, InfoType: %#v, Time: %d, AuthCorpID: %#v, ChangeType: %#v, ",
+		m.SuiteID,
+		m.InfoType,
+		m.Time.UnixNano(),
+		m.AuthCorpID,
+		m.ChangeType,
+	)
+
+	m.extras.formatInto(&sb)
+
+	sb.WriteString(" }")
+
+	return sb.String()
+}
+
+// NOTE: 同 RxMessage，以下各方法按 extras 的具体类型取出参数，通讯录变更各事件
+// 的参数接口互相包含，只断言接口会把一种事件误认作另一种。
+
+// SuiteTicket 如果指令为推送 suite_ticket，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) SuiteTicket() (SuiteInfoSuiteTicket, bool) {
+	y, ok := m.extras.(*rxSuiteInfoSuiteTicket)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// CreateAuth 如果指令为授权成功通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) CreateAuth() (SuiteInfoCreateAuth, bool) {
+	y, ok := m.extras.(*rxSuiteInfoCreateAuth)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// ChangeAuth 如果指令为变更授权通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) ChangeAuth() (SuiteInfoChangeAuth, bool) {
+	y, ok := m.extras.(*rxSuiteInfoChangeAuth)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// CancelAuth 如果指令为取消授权通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) CancelAuth() (SuiteInfoCancelAuth, bool) {
+	y, ok := m.extras.(*rxSuiteInfoCancelAuth)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// ResetPermanentCode 如果指令为重置永久授权码通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) ResetPermanentCode() (SuiteInfoResetPermanentCode, bool) {
+	y, ok := m.extras.(*rxSuiteInfoResetPermanentCode)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeCreateUser 如果指令为新增成员的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeCreateUser() (EventChangeTypeCreateUser, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeCreateUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeUpdateUser 如果指令为更新成员的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeUpdateUser() (EventChangeTypeUpdateUser, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeUpdateUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeDeleteUser 如果指令为删除成员的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeDeleteUser() (EventChangeTypeDeleteUser, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeDeleteUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeCreateParty 如果指令为新增部门的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeCreateParty() (EventChangeTypeCreateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeCreateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeUpdateParty 如果指令为更新部门的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeUpdateParty() (EventChangeTypeUpdateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeUpdateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeDeleteParty 如果指令为删除部门的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeDeleteParty() (EventChangeTypeDeleteParty, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeDeleteParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeTypeUpdateTag 如果指令为标签成员变更的通讯录变更通知，则拿出相应的参数，否则返回 nil, false
+func (m *RxSuiteInfo) EventChangeTypeUpdateTag() (EventChangeTypeUpdateTag, bool) {
+	y, ok := m.extras.(*rxEventChangeTypeUpdateTag)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// Unknown 如果指令类型未定义，则拿出原始消息体，否则返回 nil, false
+func (m *RxSuiteInfo) Unknown() (EventUnknown, bool) {
+	y, ok := m.extras.(*rxEventUnknown)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// SuiteInfoSuiteTicket 推送 suite_ticket 指令的参数
+type SuiteInfoSuiteTicket interface {
+	messageKind
+
+	// GetSuiteTicket Ticket 内容，最长为 512 字节
+	GetSuiteTicket() string
+}
+
+var _ SuiteInfoSuiteTicket = (*rxSuiteInfoSuiteTicket)(nil)
+
+func (r *rxSuiteInfoSuiteTicket) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "SuiteTicket: %#v", r.SuiteTicket)
+}
+
+func (r *rxSuiteInfoSuiteTicket) GetSuiteTicket() string {
+	return r.SuiteTicket
+}
+
+// SuiteInfoCreateAuth 授权成功通知的参数
+type SuiteInfoCreateAuth interface {
+	messageKind
+
+	// GetAuthCode 授权的 auth_code，用于获取企业的永久授权码，5 分钟内有效
+	GetAuthCode() string
+
+	// GetState 构造授权链接指定的 state 参数
+	GetState() string
+}
+
+var _ SuiteInfoCreateAuth = (*rxSuiteInfoCreateAuth)(nil)
+
+func (r *rxSuiteInfoCreateAuth) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "AuthCode: %#v, State: %#v", r.AuthCode, r.State)
+}
+
+func (r *rxSuiteInfoCreateAuth) GetAuthCode() string {
+	return r.AuthCode
+}
+
+func (r *rxSuiteInfoCreateAuth) GetState() string {
+	return r.State
+}
+
+// SuiteInfoChangeAuth 变更授权通知的参数
+type SuiteInfoChangeAuth interface {
+	messageKind
+
+	// GetState 构造授权链接指定的 state 参数
+	GetState() string
+}
+
+var _ SuiteInfoChangeAuth = (*rxSuiteInfoChangeAuth)(nil)
+
+func (r *rxSuiteInfoChangeAuth) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "State: %#v", r.State)
+}
+
+func (r *rxSuiteInfoChangeAuth) GetState() string {
+	return r.State
+}
+
+// SuiteInfoCancelAuth 取消授权通知的参数
+//
+// 除公共部分外没有其他参数，授权方见 RxSuiteInfo.AuthCorpID。
+type SuiteInfoCancelAuth interface {
+	messageKind
+
+	isCancelAuth()
+}
+
+var _ SuiteInfoCancelAuth = (*rxSuiteInfoCancelAuth)(nil)
+
+func (r *rxSuiteInfoCancelAuth) formatInto(io.Writer) {}
+
+func (r *rxSuiteInfoCancelAuth) isCancelAuth() {}
+
+// SuiteInfoResetPermanentCode 重置永久授权码通知的参数
+type SuiteInfoResetPermanentCode interface {
+	messageKind
+
+	// GetAuthCode 临时授权码，用于重新获取企业的永久授权码，5 分钟内有效
+	GetAuthCode() string
+}
+
+var _ SuiteInfoResetPermanentCode = (*rxSuiteInfoResetPermanentCode)(nil)
+
+func (r *rxSuiteInfoResetPermanentCode) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "AuthCode: %#v", r.AuthCode)
+}
+
+func (r *rxSuiteInfoResetPermanentCode) GetAuthCode() string {
+	return r.AuthCode
+}
+
+// RxSuiteInfoHandler 用来接收第三方应用指令回调的接口。
+type RxSuiteInfoHandler interface {
+	// OnIncomingSuiteInfo 一条指令到来时的回调。
+	OnIncomingSuiteInfo(info *RxSuiteInfo) error
+}
+
+// RxSuiteInfoHandlerFunc 将函数适配为 RxSuiteInfoHandler
+type RxSuiteInfoHandlerFunc func(info *RxSuiteInfo) error
+
+var _ RxSuiteInfoHandler = RxSuiteInfoHandlerFunc(nil)
+
+// OnIncomingSuiteInfo 一条指令到来时的回调。
+func (f RxSuiteInfoHandlerFunc) OnIncomingSuiteInfo(info *RxSuiteInfo) error {
+	return f(info)
+}
+
+type lowlevelSuiteEnvelopeHandler struct {
+	highlevelHandler RxSuiteInfoHandler
+	opts             httpHandlerOptions
+}
+
+var _ httpapi.EnvelopeHandler = (*lowlevelSuiteEnvelopeHandler)(nil)
+
+func (h *lowlevelSuiteEnvelopeHandler) OnIncomingEnvelope(
+	ctx context.Context,
+	rx envelope.Envelope,
+) ([]byte, error) {
+	info, err := fromSuiteEnvelope(rx.Msg)
+	if err != nil {
+		return nil, err
+	}
+	info.ctx = ctx
+
+	store := h.opts.DedupStore
+	if store == nil {
+		return nil, h.highlevelHandler.OnIncomingSuiteInfo(info)
+	}
+
+	key := info.DedupKey()
+	seen, err := store.MarkSeen(key, h.opts.DedupTTL)
+	if err != nil {
+		return nil, err
+	}
+	if seen {
+		return nil, nil
+	}
+
+	err = h.highlevelHandler.OnIncomingSuiteInfo(info)
+	if err != nil {
+		// 处理失败，让企业微信的重试能被再次处理
+		_ = store.Forget(key)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// suiteInfoAck 指令回调要求的响应体
+var suiteInfoAck = []byte("success")
+
+// SuiteHTTPHandler 第三方应用指令回调的 HTTP handler
+type SuiteHTTPHandler struct {
+	inner *httpapi.LowlevelHandler
+}
+
+var _ http.Handler = (*SuiteHTTPHandler)(nil)
+
+// NewSuiteHTTPHandler 构造一个第三方应用指令回调的 HTTP handler
+//
+// token 与 encodingAESKey 为第三方应用的回调配置。与企业回调不同，指令回调以
+// InfoType 区分类型，加密时的 ReceiveID 为 SuiteID。
+//
+// opts 中 WithDedup、WithReplayProtection、WithMaxBodySize、WithSourceFilter、
+// WithExpectedReceiveID 与 WithRejectHook 同样适用于指令回调；其余只涉及
+// RxMessage 的参数会被忽略。
+func NewSuiteHTTPHandler(
+	token string,
+	encodingAESKey string,
+	handler RxSuiteInfoHandler,
+	opts ...HTTPHandlerOption,
+) (*SuiteHTTPHandler, error) {
+	optionsObj := defaultHTTPHandlerOptions()
+
+	for _, o := range opts {
+		o.applyTo(&optionsObj)
+	}
+
+	lleh := &lowlevelSuiteEnvelopeHandler{
+		highlevelHandler: handler,
+		opts:             optionsObj,
+	}
+
+	llHandler, err := httpapi.NewLowlevelHandler(token, encodingAESKey, lleh, optionsObj.processorOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	llHandler.SetAckBody(suiteInfoAck)
+	optionsObj.applyToLowlevel(llHandler)
+
+	obj := SuiteHTTPHandler{
+		inner: llHandler,
+	}
+
+	return &obj, nil
+}
+
+func (h *SuiteHTTPHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	h.inner.ServeHTTP(rw, r)
+}
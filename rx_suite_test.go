@@ -0,0 +1,164 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFromSuiteEnvelope(t *testing.T) {
+	c.Convey("解析第三方应用指令回调", t, func() {
+		c.Convey("suite_ticket", func() {
+			body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><InfoType> <![CDATA[suite_ticket]]></InfoType><TimeStamp>1403610513</TimeStamp><SuiteTicket><![CDATA[asdfasfdasdfasdf]]></SuiteTicket></xml>")
+			info, err := fromSuiteEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(info.SuiteID, c.ShouldEqual, "ww4asffe99e54c0f4c")
+			c.So(info.Time.Unix(), c.ShouldEqual, 1403610513)
+
+			x, ok := info.SuiteTicket()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(x.GetSuiteTicket(), c.ShouldEqual, "asdfasfdasdfasdf")
+		})
+
+		c.Convey("create_auth", func() {
+			body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0fxxxx]]></SuiteId><AuthCode><![CDATA[AUTHCODE]]></AuthCode><InfoType><![CDATA[create_auth]]></InfoType><TimeStamp>1403610513</TimeStamp><State><![CDATA[123]]></State></xml>")
+			info, err := fromSuiteEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(info.InfoType, c.ShouldEqual, SuiteInfoTypeCreateAuth)
+
+			x, ok := info.CreateAuth()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(x.GetAuthCode(), c.ShouldEqual, "AUTHCODE")
+			c.So(x.GetState(), c.ShouldEqual, "123")
+		})
+
+		c.Convey("cancel_auth", func() {
+			body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><InfoType><![CDATA[cancel_auth]]></InfoType><TimeStamp>1403610513</TimeStamp><AuthCorpId><![CDATA[wxf8b4f85f3a794e77]]></AuthCorpId></xml>")
+			info, err := fromSuiteEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(info.AuthCorpID, c.ShouldEqual, "wxf8b4f85f3a794e77")
+
+			_, ok := info.CancelAuth()
+			c.So(ok, c.ShouldBeTrue)
+		})
+
+		c.Convey("change_contact", func() {
+			body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><AuthCorpId><![CDATA[wxf8b4f85f3a794e77]]></AuthCorpId><InfoType><![CDATA[change_contact]]></InfoType><TimeStamp>1403610513</TimeStamp><ChangeType><![CDATA[create_user]]></ChangeType><UserID><![CDATA[zhangsan]]></UserID><Name><![CDATA[张三]]></Name></xml>")
+			info, err := fromSuiteEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(info.ChangeType, c.ShouldEqual, ChangeTypeCreateUser)
+
+			x, ok := info.EventChangeTypeCreateUser()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(x.GetUserID(), c.ShouldEqual, "zhangsan")
+
+			_, ok = info.EventChangeTypeDeleteUser()
+			c.So(ok, c.ShouldBeFalse)
+			_, ok = info.EventChangeTypeUpdateUser()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("change_contact create_party", func() {
+			body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><AuthCorpId><![CDATA[wxf8b4f85f3a794e77]]></AuthCorpId><InfoType><![CDATA[change_contact]]></InfoType><TimeStamp>1403610513</TimeStamp><ChangeType><![CDATA[create_party]]></ChangeType><Id>2</Id><Name><![CDATA[张三]]></Name><ParentId><![CDATA[1]]></ParentId><Order>1</Order></xml>")
+			info, err := fromSuiteEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			x, ok := info.EventChangeTypeCreateParty()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(x.GetID(), c.ShouldEqual, 2)
+
+			_, ok = info.EventChangeTypeUpdateParty()
+			c.So(ok, c.ShouldBeFalse)
+			_, ok = info.EventChangeTypeDeleteParty()
+			c.So(ok, c.ShouldBeFalse)
+		})
+	})
+}
+
+func TestSuiteTicketStore(t *testing.T) {
+	c.Convey("保存 suite_ticket", t, func() {
+		//nolint: gosec  // randomly generated for test purposes only
+		cred := CallbackCredential{
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+		store := NewMemorySuiteTicketStore()
+		provider := NewSuiteTicketProvider(store, "ww4asffe99e54c0f4c")
+
+		_, err := provider.GetSuiteTicket(context.Background())
+		c.So(err, c.ShouldEqual, ErrSuiteTicketNotFound)
+
+		h, err := NewSuiteHTTPHandler(cred.Token, cred.EncodingAESKey, SaveSuiteTicket(store, nil))
+		c.So(err, c.ShouldBeNil)
+
+		body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><InfoType><![CDATA[suite_ticket]]></InfoType><TimeStamp>1403610513</TimeStamp><SuiteTicket><![CDATA[asdfasfdasdfasdf]]></SuiteTicket></xml>")
+		req, err := makeTestCallbackRequest("http://a.b/suite", cred, "ww4asffe99e54c0f4c", "", body)
+		c.So(err, c.ShouldBeNil)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		c.So(rec.Code, c.ShouldEqual, http.StatusOK)
+		c.So(rec.Body.String(), c.ShouldEqual, "success")
+
+		ticket, err := provider.GetSuiteTicket(context.Background())
+		c.So(err, c.ShouldBeNil)
+		c.So(ticket, c.ShouldEqual, "asdfasfdasdfasdf")
+	})
+}
+
+func TestSuiteHTTPHandlerOptions(t *testing.T) {
+	c.Convey("指令回调 handler 的构造参数", t, func() {
+		//nolint: gosec  // randomly generated for test purposes only
+		cred := CallbackCredential{
+			Token:          "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+			EncodingAESKey: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+		}
+		body := []byte("<xml><SuiteId><![CDATA[ww4asffe99e54c0f4c]]></SuiteId><InfoType><![CDATA[suite_ticket]]></InfoType><TimeStamp>1403610513</TimeStamp><SuiteTicket><![CDATA[asdfasfdasdfasdf]]></SuiteTicket></xml>")
+
+		calls := 0
+		var rejected []error
+		h, err := NewSuiteHTTPHandler(
+			cred.Token,
+			cred.EncodingAESKey,
+			RxSuiteInfoHandlerFunc(func(*RxSuiteInfo) error {
+				calls++
+				return nil
+			}),
+			WithDedup(NewMemoryDedupStore(16), DefaultDedupTTL),
+			WithMaxBodySize(1024),
+			WithRejectHook(func(_ *http.Request, err error) {
+				rejected = append(rejected, err)
+			}),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		c.Convey("重试推送只处理一次", func() {
+			for range 3 {
+				req, err := makeTestCallbackRequest("http://a.b/suite", cred, "ww4asffe99e54c0f4c", "", body)
+				c.So(err, c.ShouldBeNil)
+
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				c.So(rec.Code, c.ShouldEqual, http.StatusOK)
+				c.So(rec.Body.String(), c.ShouldEqual, "success")
+			}
+			c.So(calls, c.ShouldEqual, 1)
+		})
+
+		c.Convey("请求体过大", func() {
+			req, err := makeTestCallbackRequest("http://a.b/suite", cred, "ww4asffe99e54c0f4c", "", bytes.Repeat(body, 8))
+			c.So(err, c.ShouldBeNil)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			c.So(rec.Code, c.ShouldEqual, http.StatusRequestEntityTooLarge)
+			c.So(errors.Is(rejected[0], ErrCallbackBodyTooLarge), c.ShouldBeTrue)
+			c.So(calls, c.ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrSuiteTicketNotFound 尚未收到给定第三方应用的 suite_ticket
+var ErrSuiteTicketNotFound = errors.New("go-workwx: suite_ticket not found")
+
+// SuiteTicketStore 保存第三方应用 suite_ticket 的存储
+//
+// 企业微信每十分钟推送一次 suite_ticket，获取第三方应用凭证时需要用到最新的一个。
+// 实现需要是并发安全的；多实例部署时需要共享的实现。
+type SuiteTicketStore interface {
+	// SetSuiteTicket 保存给定第三方应用最新的 suite_ticket
+	SetSuiteTicket(ctx context.Context, suiteID string, ticket string) error
+
+	// GetSuiteTicket 取回给定第三方应用最新的 suite_ticket
+	//
+	// 尚未收到过时返回 ErrSuiteTicketNotFound。
+	GetSuiteTicket(ctx context.Context, suiteID string) (string, error)
+}
+
+// MemorySuiteTicketStore 基于内存的 SuiteTicketStore
+type MemorySuiteTicketStore struct {
+	mu      sync.RWMutex
+	tickets map[string]string
+}
+
+var _ SuiteTicketStore = (*MemorySuiteTicketStore)(nil)
+
+// NewMemorySuiteTicketStore 构造一个空的 MemorySuiteTicketStore
+func NewMemorySuiteTicketStore() *MemorySuiteTicketStore {
+	return &MemorySuiteTicketStore{
+		mu:      sync.RWMutex{},
+		tickets: make(map[string]string),
+	}
+}
+
+// SetSuiteTicket 保存给定第三方应用最新的 suite_ticket
+func (s *MemorySuiteTicketStore) SetSuiteTicket(_ context.Context, suiteID string, ticket string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickets[suiteID] = ticket
+	return nil
+}
+
+// GetSuiteTicket 取回给定第三方应用最新的 suite_ticket
+func (s *MemorySuiteTicketStore) GetSuiteTicket(_ context.Context, suiteID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ticket, ok := s.tickets[suiteID]
+	if !ok {
+		return "", ErrSuiteTicketNotFound
+	}
+
+	return ticket, nil
+}
+
+// SaveSuiteTicket 将收到的 suite_ticket 存入 store，再把指令交给 next 处理
+//
+// next 可以为 nil，此时只保存 suite_ticket，忽略其他指令。
+func SaveSuiteTicket(store SuiteTicketStore, next RxSuiteInfoHandler) RxSuiteInfoHandler {
+	return RxSuiteInfoHandlerFunc(func(info *RxSuiteInfo) error {
+		if x, ok := info.SuiteTicket(); ok {
+			err := store.SetSuiteTicket(info.Context(), info.SuiteID, x.GetSuiteTicket())
+			if err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		return next.OnIncomingSuiteInfo(info)
+	})
+}
+
+// SuiteTicketProvider 给定第三方应用最新 suite_ticket 的提供者
+//
+// suite_ticket 不是 access_token，因此不复用 ITokenProvider。
+type SuiteTicketProvider interface {
+	// GetSuiteTicket 取回最新的 suite_ticket，尚未收到过时返回 ErrSuiteTicketNotFound
+	GetSuiteTicket(ctx context.Context) (string, error)
+}
+
+type suiteTicketProvider struct {
+	store   SuiteTicketStore
+	suiteID string
+}
+
+var _ SuiteTicketProvider = (*suiteTicketProvider)(nil)
+
+// NewSuiteTicketProvider 从 store 中提供给定第三方应用最新的 suite_ticket
+func NewSuiteTicketProvider(store SuiteTicketStore, suiteID string) SuiteTicketProvider {
+	return &suiteTicketProvider{store: store, suiteID: suiteID}
+}
+
+func (p *suiteTicketProvider) GetSuiteTicket(ctx context.Context) (string, error) {
+	return p.store.GetSuiteTicket(ctx, p.suiteID)
+}
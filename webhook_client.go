@@ -2,8 +2,10 @@ package workwx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 )
 
@@ -55,7 +57,12 @@ func (c *WebhookClient) composeQyapiURLWithKey(path string, req any) (*url.URL,
 	return base, nil
 }
 
-func (c *WebhookClient) executeQyapiJSONPost(path string, req any, respObj any) error {
+func (c *WebhookClient) executeQyapiJSONPostWithContext(
+	ctx context.Context,
+	path string,
+	req any,
+	respObj any,
+) error {
 	url, err := c.composeQyapiURLWithKey(path, req)
 	if err != nil {
 		return err
@@ -67,7 +74,13 @@ func (c *WebhookClient) executeQyapiJSONPost(path string, req any, respObj any)
 		return makeReqMarshalErr(err)
 	}
 
-	resp, err := c.opts.HTTP.Post(urlStr, "application/json", bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, bytes.NewReader(body))
+	if err != nil {
+		return makeRequestErr(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.opts.HTTP.Do(httpReq)
 	if err != nil {
 		return makeRequestErr(err)
 	}
@@ -1,5 +1,10 @@
 package workwx
 
+import (
+	"context"
+	"errors"
+)
+
 // MentionAll 表示提醒所有人（“@所有人”）的特殊标记
 const MentionAll = "@all"
 
@@ -20,21 +25,8 @@ func (c *WebhookClient) SendTextMessage(
 	content string,
 	mentions *Mentions,
 ) error {
-	params := map[string]any{
-		"content": content,
-	}
-
-	if mentions != nil {
-		if len(mentions.UserIDs) > 0 {
-			params["mentioned_list"] = mentions.UserIDs
-		}
-
-		if len(mentions.Mobiles) > 0 {
-			params["mentioned_mobile_list"] = mentions.Mobiles
-		}
-	}
-
-	return c.sendMessage("text", params)
+	_, err := c.Send(context.Background(), nil, uncheckedMessage{TextMessage{Content: content, Mentions: mentions}}, nil)
+	return err
 }
 
 // SendMarkdownMessage 发送 Markdown 消息
@@ -44,27 +36,51 @@ func (c *WebhookClient) SendTextMessage(
 func (c *WebhookClient) SendMarkdownMessage(
 	content string,
 ) error {
-	params := map[string]any{
-		"content": content,
+	_, err := c.Send(context.Background(), nil, uncheckedMessage{MarkdownMessage{Content: content}}, nil)
+	return err
+}
+
+var _ MessageSender = (*WebhookClient)(nil)
+
+var errWebhookRecipient = errors.New("webhook messages are sent to the group bound to the key, recipient must be empty")
+
+// Send 发送消息
+//
+// 群机器人消息总是发往 webhook key 所绑定的群，recipient 须为 nil 或空值；
+// 群机器人不支持保密消息。群机器人接口不返回消息 ID，返回的 SendResult 为空值。
+func (c *WebhookClient) Send(
+	ctx context.Context,
+	recipient *Recipient,
+	msg Message,
+	opts *SendOptions,
+) (*SendResult, error) {
+	if recipient != nil && !recipient.isEmpty() {
+		return nil, errWebhookRecipient
+	}
+	if opts != nil {
+		if err := opts.validateFor(SendChannelWebhook); err != nil {
+			return nil, err
+		}
 	}
 
-	return c.sendMessage("markdown", params)
-}
+	content, err := messageContent(msg, SendChannelWebhook)
+	if err != nil {
+		return nil, err
+	}
 
-// sendMessage 发送消息底层接口
-func (c *WebhookClient) sendMessage(
-	msgtype string,
-	content map[string]any,
-) error {
 	req := map[string]any{
-		"msgtype": msgtype,
-		msgtype:   content,
+		"msgtype":         msg.MessageType(),
+		msg.MessageType(): content,
 	}
 
-	err := c.executeQyapiJSONPost("/cgi-bin/webhook/send", req, nil)
+	var resp respCommon
+	err = c.executeQyapiJSONPostWithContext(ctx, "/cgi-bin/webhook/send", req, &resp)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return nil, bizErr
 	}
 
-	return nil
+	return &SendResult{}, nil
 }
@@ -0,0 +1,163 @@
+package workwxtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EnxZhou/go-workwx"
+)
+
+// ErrUnsupportedEvent 没有给定消息、事件类型的样例
+var ErrUnsupportedEvent = errors.New("workwxtest: unsupported message or event type")
+
+// Field 回调消息体中的一个字段
+type Field struct {
+	// Name 元素名
+	Name string
+	// Value 元素内容，以 CDATA 形式输出
+	Value string
+	// Raw 为真时 Value 为原样输出的 XML 片段，用于嵌套结构
+	Raw bool
+}
+
+// Event 一条模拟的回调消息或事件
+type Event struct {
+	// ToUserName 接收方，即企业的 CorpID
+	ToUserName string
+	// FromUserName 发送者的 UserID，系统事件为 sys
+	FromUserName string
+	// CreateTime 消息创建时间
+	CreateTime time.Time
+	// MsgType 消息类型
+	MsgType workwx.MessageType
+	// Event 事件类型，MsgType 为 event 时存在
+	Event workwx.EventType
+	// ChangeType 变更类型
+	ChangeType workwx.ChangeType
+	// MsgID 消息 ID，为零则不输出
+	MsgID int64
+	// AgentID 企业应用 ID，为零则不输出
+	AgentID int64
+	// Fields 消息或事件特有的字段
+	Fields []Field
+}
+
+// Set 设置给定字段的内容，字段不存在时追加到末尾
+func (e *Event) Set(name string, value string) {
+	for i := range e.Fields {
+		if e.Fields[i].Name == name {
+			e.Fields[i] = Field{Name: name, Value: value, Raw: false}
+			return
+		}
+	}
+
+	e.Fields = append(e.Fields, Field{Name: name, Value: value, Raw: false})
+}
+
+// XML 输出解密后的回调消息体
+func (e *Event) XML() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("<xml>")
+	writeCDATAElement(&buf, "ToUserName", e.ToUserName)
+	writeCDATAElement(&buf, "FromUserName", e.FromUserName)
+	writeElement(&buf, "CreateTime", strconv.FormatInt(e.CreateTime.Unix(), 10))
+	writeCDATAElement(&buf, "MsgType", string(e.MsgType))
+	if e.Event != "" {
+		writeCDATAElement(&buf, "Event", string(e.Event))
+	}
+	if e.ChangeType != "" {
+		writeCDATAElement(&buf, "ChangeType", string(e.ChangeType))
+	}
+	for _, f := range e.Fields {
+		if f.Raw {
+			writeElement(&buf, f.Name, f.Value)
+		} else {
+			writeCDATAElement(&buf, f.Name, f.Value)
+		}
+	}
+	if e.MsgID != 0 {
+		writeElement(&buf, "MsgId", strconv.FormatInt(e.MsgID, 10))
+	}
+	if e.AgentID != 0 {
+		writeElement(&buf, "AgentID", strconv.FormatInt(e.AgentID, 10))
+	}
+	buf.WriteString("</xml>")
+
+	return buf.Bytes()
+}
+
+func writeElement(buf *bytes.Buffer, name string, inner string) {
+	_, _ = fmt.Fprintf(buf, "<%s>%s</%s>", name, inner, name)
+}
+
+func writeCDATAElement(buf *bytes.Buffer, name string, value string) {
+	// "]]>" 不能出现在 CDATA 中，拆成两段
+	value = strings.ReplaceAll(value, "]]>", "]]]]><![CDATA[>")
+	writeElement(buf, name, "<![CDATA["+value+"]]>")
+}
+
+// EventKind 消息、事件类型在样例表中的键
+//
+// 形如 text、event/click、event/change_contact/create_user。
+func EventKind(msgType workwx.MessageType, event workwx.EventType, changeType workwx.ChangeType) string {
+	parts := []string{string(msgType)}
+	if event != "" {
+		parts = append(parts, string(event))
+	}
+	if changeType != "" {
+		parts = append(parts, string(changeType))
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// SupportedEventKinds 返回所有有样例的消息、事件类型，已排序
+func SupportedEventKinds() []string {
+	result := make([]string, 0, len(samples))
+	for k := range samples {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// NewEvent 按样例构造一条给定类型的回调消息或事件
+//
+// 返回的 Event 各字段均可再行修改。
+func NewEvent(
+	msgType workwx.MessageType,
+	event workwx.EventType,
+	changeType workwx.ChangeType,
+) (*Event, error) {
+	s, ok := samples[EventKind(msgType, event, changeType)]
+	if !ok {
+		return nil, ErrUnsupportedEvent
+	}
+
+	obj := Event{
+		ToUserName:   "",
+		FromUserName: "zhangsan",
+		CreateTime:   time.Now(),
+		MsgType:      msgType,
+		Event:        event,
+		ChangeType:   changeType,
+		MsgID:        0,
+		AgentID:      0,
+		Fields:       append([]Field(nil), s.fields...),
+	}
+	if msgType != workwx.MessageTypeEvent {
+		obj.MsgID = time.Now().UnixNano() & 0x7fffffff
+	}
+	if s.system {
+		obj.FromUserName = "sys"
+	}
+
+	return &obj, nil
+}
@@ -0,0 +1,141 @@
+package workwxtest
+
+// sample 一种消息、事件类型的样例
+type sample struct {
+	// system 是否为系统推送的事件，此时发送者为 sys
+	system bool
+	fields []Field
+}
+
+func f(name string, value string) Field {
+	return Field{Name: name, Value: value, Raw: false}
+}
+
+func raw(name string, inner string) Field {
+	return Field{Name: name, Value: inner, Raw: true}
+}
+
+var externalContactFields = []Field{
+	f("UserID", "zhangsan"),
+	f("ExternalUserID", "woAJ2GCAAAXtWyujaWJHDDGi0mAAAA"),
+	f("State", "teststate"),
+	f("WelcomeCode", "WELCOMECODE"),
+}
+
+var userFields = []Field{
+	f("UserID", "zhangsan"),
+	f("Name", "张三"),
+	f("Department", "1,2,3"),
+	f("MainDepartment", "1"),
+	f("IsLeaderInDept", "1,0,0"),
+	f("Position", "产品经理"),
+	f("Mobile", "13800000000"),
+	raw("Gender", "1"),
+	f("Email", "zhangsan@gzdev.com"),
+	raw("Status", "1"),
+	f("Alias", "zhangsan"),
+	f("Telephone", "020-123456"),
+	f("Address", "广州市"),
+}
+
+var picsFields = []Field{
+	f("EventKey", "6"),
+	raw("SendPicsInfo", "<Count>1</Count><PicList><item><PicMd5Sum><![CDATA[1b5f7c23b5bf75682a53e7b6d163e185]]></PicMd5Sum></item></PicList>"),
+}
+
+var scanCodeFields = []Field{
+	f("EventKey", "6"),
+	raw("ScanCodeInfo", "<ScanType><![CDATA[qrcode]]></ScanType><ScanResult><![CDATA[1]]></ScanResult>"),
+}
+
+var samples = map[string]sample{
+	// 普通消息
+	"text":     {fields: []Field{f("Content", "this is a test")}},
+	"image":    {fields: []Field{f("PicUrl", "https://example.com/pic.png"), f("MediaId", "media_id")}},
+	"voice":    {fields: []Field{f("MediaId", "media_id"), f("Format", "amr")}},
+	"video":    {fields: []Field{f("MediaId", "media_id"), f("ThumbMediaId", "thumb_media_id")}},
+	"location": {fields: []Field{raw("Location_X", "23.134521"), raw("Location_Y", "113.358803"), raw("Scale", "20"), f("Label", "位置信息"), f("AppType", "wxwork")}},
+	"link":     {fields: []Field{f("Title", "this is a title"), f("Description", "this is a description"), f("Url", "https://example.com"), f("PicUrl", "https://example.com/pic.png")}},
+
+	// 应用事件
+	"event/subscribe":          {fields: []Field{f("EventKey", "")}},
+	"event/unsubscribe":        {fields: []Field{f("EventKey", "")}},
+	"event/enter_agent":        {fields: []Field{f("EventKey", "")}},
+	"event/LOCATION":           {fields: []Field{raw("Latitude", "23.104105"), raw("Longitude", "113.320107"), raw("Precision", "65.000000"), f("AppType", "wxwork")}},
+	"event/click":              {fields: []Field{f("EventKey", "EVENTKEY")}},
+	"event/view":               {fields: []Field{f("EventKey", "https://example.com")}},
+	"event/scancode_push":      {fields: scanCodeFields},
+	"event/scancode_waitmsg":   {fields: scanCodeFields},
+	"event/pic_sysphoto":       {fields: picsFields},
+	"event/pic_photo_or_album": {fields: picsFields},
+	"event/pic_weixin":         {fields: picsFields},
+	"event/location_select": {fields: []Field{
+		f("EventKey", "6"),
+		raw("SendLocationInfo", "<Location_X><![CDATA[23]]></Location_X><Location_Y><![CDATA[113]]></Location_Y><Scale><![CDATA[15]]></Scale><Label><![CDATA[ 广州市海珠区客村艺苑路 106号]]></Label><Poiname><![CDATA[]]></Poiname>"),
+	}},
+	"event/view_miniprogram": {fields: []Field{f("EventKey", "pages/index")}},
+	"event/template_card_event": {fields: []Field{
+		f("EventKey", "key111"),
+		f("TaskId", "taskid111"),
+		f("CardType", "vote_interaction"),
+		f("ResponseCode", "ResponseCode"),
+		raw("SelectedItems", "<SelectedItem><QuestionKey><![CDATA[QuestionKey1]]></QuestionKey><OptionIds><OptionId><![CDATA[OptionId1]]></OptionId><OptionId><![CDATA[OptionId2]]></OptionId></OptionIds></SelectedItem>"),
+	}},
+	"event/template_card_menu_event": {fields: []Field{
+		f("EventKey", "key111"),
+		f("TaskId", "taskid111"),
+		f("CardType", "text_notice"),
+		f("ResponseCode", "ResponseCode"),
+	}},
+	"event/taskcard_click": {fields: []Field{f("EventKey", "key111"), f("TaskId", "taskid111")}},
+	"event/batch_job_result": {system: true, fields: []Field{
+		raw("BatchJob", "<JobId><![CDATA[S0MrnndvRG5fadSlLwiBqiDDbM143UqTmKP3152FZk4]]></JobId><JobType><![CDATA[sync_user]]></JobType><ErrCode>0</ErrCode><ErrMsg><![CDATA[ok]]></ErrMsg>"),
+	}},
+	"event/open_approval_change": {fields: []Field{
+		raw("ApprovalInfo", "<ThirdNo><![CDATA[201806010001]]></ThirdNo><OpenSpName><![CDATA[付款]]></OpenSpName><OpenTemplateId><![CDATA[1234567890]]></OpenTemplateId><OpenSpStatus>1</OpenSpStatus><ApplyTime>1527837645</ApplyTime><ApplyUserName><![CDATA[xiaoming]]></ApplyUserName><ApplyUserId><![CDATA[1]]></ApplyUserId><ApplyUserParty><![CDATA[产品部]]></ApplyUserParty><ApplyUserImage><![CDATA[http://www.qq.com/xxx.png]]></ApplyUserImage><ApprovalNodes><ApprovalNode><NodeStatus>1</NodeStatus><NodeAttr>1</NodeAttr><NodeType>1</NodeType><Items><Item><ItemName><![CDATA[xiaohong]]></ItemName><ItemUserId><![CDATA[2]]></ItemUserId><ItemImage><![CDATA[http://www.qq.com/xxx.png]]></ItemImage><ItemStatus>1</ItemStatus><ItemSpeech><![CDATA[]]></ItemSpeech><ItemOpTime>0</ItemOpTime></Item></Items></ApprovalNode></ApprovalNodes><NotifyNodes><NotifyNode><ItemName><![CDATA[xiaogang]]></ItemName><ItemUserId><![CDATA[3]]></ItemUserId><ItemImage><![CDATA[http://www.qq.com/xxx.png]]></ItemImage></NotifyNode></NotifyNodes><approverstep>0</approverstep>"),
+	}},
+	"event/sys_approval_change": {system: true, fields: []Field{
+		raw("ApprovalInfo", "<SpNo>201909270001</SpNo><SpName><![CDATA[请假]]></SpName><SpStatus>1</SpStatus><TemplateId><![CDATA[3TkaH5KFbrG9heEQWLJjhgpFNDqrP4Ly8G8vBs]]></TemplateId><ApplyTime>1569584428</ApplyTime><Applyer><UserId><![CDATA[zhangsan]]></UserId><Party><![CDATA[1]]></Party></Applyer><SpRecord><SpStatus>1</SpStatus><ApproverAttr>1</ApproverAttr><Details><Approver><UserId><![CDATA[lisi]]></UserId></Approver><Speech><![CDATA[]]></Speech><SpStatus>1</SpStatus><SpTime>0</SpTime></Details></SpRecord><Notifyer><UserId><![CDATA[wangwu]]></UserId></Notifyer><StatuChangeEvent>1</StatuChangeEvent>"),
+	}},
+	"event/kf_msg_or_event": {system: true, fields: []Field{f("Token", "ENCApHxnGDNAVNY4AaSJKj4Tb5mwsEMzxhFmHVGcra996NR"), f("OpenKfId", "wkxxxxxxx")}},
+
+	// 通讯录变更事件
+	"event/change_contact/create_user": {system: true, fields: userFields},
+	"event/change_contact/update_user": {system: true, fields: append([]Field{f("NewUserID", "zhangsan001")}, userFields...)},
+	"event/change_contact/delete_user": {system: true, fields: []Field{f("UserID", "zhangsan")}},
+	"event/change_contact/create_party": {system: true, fields: []Field{
+		raw("Id", "2"), f("Name", "张三"), raw("ParentId", "1"), raw("Order", "1"),
+	}},
+	"event/change_contact/update_party": {system: true, fields: []Field{raw("Id", "2"), f("Name", "张三"), raw("ParentId", "1")}},
+	"event/change_contact/delete_party": {system: true, fields: []Field{raw("Id", "2")}},
+	"event/change_contact/update_tag": {system: true, fields: []Field{
+		raw("TagId", "1"), f("AddUserItems", "zhangsan,lisi"), f("DelUserItems", "zhangsan1,lisi1"), f("AddPartyItems", "1,2"), f("DelPartyItems", "3,4"),
+	}},
+
+	// 客户联系事件
+	"event/change_external_contact/add_external_contact":      {system: true, fields: externalContactFields},
+	"event/change_external_contact/edit_external_contact":     {system: true, fields: externalContactFields[:3]},
+	"event/change_external_contact/add_half_external_contact": {system: true, fields: externalContactFields},
+	"event/change_external_contact/del_external_contact":      {system: true, fields: externalContactFields[:2]},
+	"event/change_external_contact/del_follow_user":           {system: true, fields: externalContactFields[:2]},
+	"event/change_external_contact/create_user":               {system: true, fields: userFields},
+	"event/change_external_contact/update_user":               {system: true, fields: append([]Field{f("NewUserID", "zhangsan001")}, userFields...)},
+	"event/change_external_contact/transfer_fail": {system: true, fields: []Field{
+		f("FailReason", "customer_refused"), f("UserID", "zhangsan"), f("ExternalUserID", "woAJ2GCAAAXtWyujaWJHDDGi0mAAAA"),
+	}},
+	"event/change_external_chat/create":  {system: true, fields: []Field{f("ChatId", "CHAT_ID")}},
+	"event/change_external_chat/dismiss": {system: true, fields: []Field{f("ChatId", "CHAT_ID")}},
+	"event/change_external_chat/update": {system: true, fields: []Field{
+		f("ChatId", "CHAT_ID"),
+		f("UpdateDetail", "add_member"),
+		raw("JoinScene", "1"),
+		raw("MemChangeCnt", "2"),
+		raw("MemChangeList", "<Item><![CDATA[Jack]]></Item><Item><![CDATA[Rose]]></Item>"),
+		f("LastMemVer", "9c3f97c2ada667dfb5f6d03308d963e1"),
+		f("CurMemVer", "71217227bbd112ecfe3a49c482195cb4"),
+	}},
+	"event/change_external_tag/create":  {system: true, fields: []Field{f("Id", "TAG_ID"), f("TagType", "tag"), raw("StrategyId", "1")}},
+	"event/change_external_tag/update":  {system: true, fields: []Field{f("Id", "TAG_ID"), f("TagType", "tag"), raw("StrategyId", "1")}},
+	"event/change_external_tag/delete":  {system: true, fields: []Field{f("Id", "TAG_ID"), f("TagType", "tag"), raw("StrategyId", "1")}},
+	"event/change_external_tag/shuffle": {system: true, fields: []Field{f("Id", ""), raw("StrategyId", "1")}},
+}
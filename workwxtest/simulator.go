@@ -0,0 +1,319 @@
+package workwxtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/encryptor"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/envelope"
+	"github.com/EnxZhou/go-workwx/internal/lowlevel/signature"
+)
+
+// ErrEchoMismatch 回调 URL 验证请求的响应与 echostr 明文不符
+var ErrEchoMismatch = errors.New("workwxtest: echo test response mismatch")
+
+// Simulator 模拟企业微信向回调 URL 推送加密、签名后的回调
+//
+// 便于在本地开发、测试回调处理逻辑，无需公网隧道。
+type Simulator struct {
+	// Client 发送回调请求所用的 HTTP 客户端，为 nil 则使用 http.DefaultClient
+	Client *http.Client
+
+	token  string
+	corpID string
+	ep     *envelope.Processor
+	enc    *encryptor.WorkwxEncryptor
+}
+
+// NewSimulator 以给定的回调凭据与 CorpID 构造一个 Simulator
+func NewSimulator(token string, encodingAESKey string, corpID string) (*Simulator, error) {
+	ep, err := envelope.NewProcessor(token, encodingAESKey)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := encryptor.NewWorkwxEncryptor(encodingAESKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Simulator{
+		Client: nil,
+		token:  token,
+		corpID: corpID,
+		ep:     ep,
+		enc:    enc,
+	}, nil
+}
+
+// DeliveryResult 一次回调推送的结果
+type DeliveryResult struct {
+	// StatusCode 回调响应的 HTTP 状态码
+	StatusCode int
+	// Body 回调响应体
+	Body []byte
+	// Reply 解密后的被动回复消息体，没有被动回复时为 nil
+	Reply []byte
+}
+
+// NewCallbackRequest 构造一个推送给定消息体的回调请求
+//
+// 与企业微信一样，ToUserName 与 AgentID 以明文附在加密包外。
+func (s *Simulator) NewCallbackRequest(
+	ctx context.Context,
+	endpoint string,
+	agentID int64,
+	msg []byte,
+) (*http.Request, error) {
+	// 出站包与入站包的 Encrypt 与签名算法一致
+	pkt, err := s.ep.MakeOutgoingEnvelopeWithReceiveID(msg, []byte(s.corpID))
+	if err != nil {
+		return nil, err
+	}
+	var x xmlEncryptedEnvelope
+	err = xml.Unmarshal(pkt, &x)
+	if err != nil {
+		return nil, err
+	}
+
+	var agentIDStr string
+	if agentID != 0 {
+		agentIDStr = strconv.FormatInt(agentID, 10)
+	}
+	body := fmt.Sprintf(
+		"<xml><ToUserName><![CDATA[%s]]></ToUserName><AgentID><![CDATA[%s]]></AgentID><Encrypt><![CDATA[%s]]></Encrypt></xml>",
+		s.corpID,
+		agentIDStr,
+		x.Encrypt,
+	)
+
+	u, err := signedURL(endpoint, map[string]string{
+		"msg_signature": x.MsgSignature,
+		"timestamp":     strconv.FormatInt(x.Timestamp, 10),
+		"nonce":         x.Nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+
+	return req, nil
+}
+
+// NewEchoTestRequest 构造一个回调 URL 验证请求，echo 为期望原样返回的明文
+func (s *Simulator) NewEchoTestRequest(ctx context.Context, endpoint string, echo string) (*http.Request, error) {
+	echoStr, err := s.enc.Encrypt(&encryptor.WorkwxPayload{
+		Msg:       []byte(echo),
+		ReceiveID: []byte(s.corpID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := makeNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := signedURL(endpoint, map[string]string{
+		"msg_signature": signature.MakeDevMsgSignature(s.token, ts, nonce, echoStr),
+		"timestamp":     ts,
+		"nonce":         nonce,
+		"echostr":       echoStr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+}
+
+// Deliver 将事件加密、签名后推送到给定的回调 URL
+func (s *Simulator) Deliver(ctx context.Context, endpoint string, e *Event) (*DeliveryResult, error) {
+	req, err := s.NewCallbackRequest(ctx, endpoint, e.AgentID, s.withCorpID(e).XML())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roundTrip(req, s.doHTTP)
+}
+
+// DeliverTo 将事件加密、签名后直接交给给定的 http.Handler 处理
+func (s *Simulator) DeliverTo(h http.Handler, e *Event) (*DeliveryResult, error) {
+	req, err := s.NewCallbackRequest(context.Background(), "/", e.AgentID, s.withCorpID(e).XML())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roundTrip(req, serveWith(h))
+}
+
+// EchoTest 向给定的回调 URL 发起回调 URL 验证，响应与 echostr 明文不符时返回 ErrEchoMismatch
+func (s *Simulator) EchoTest(ctx context.Context, endpoint string) error {
+	return s.echoTest(ctx, endpoint, s.doHTTP)
+}
+
+// EchoTestTo 对给定的 http.Handler 发起回调 URL 验证
+func (s *Simulator) EchoTestTo(h http.Handler) error {
+	return s.echoTest(context.Background(), "/", serveWith(h))
+}
+
+func (s *Simulator) echoTest(
+	ctx context.Context,
+	endpoint string,
+	do func(*http.Request) (*http.Response, error),
+) error {
+	echo, err := makeNonce()
+	if err != nil {
+		return err
+	}
+
+	req, err := s.NewEchoTestRequest(ctx, endpoint, echo)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.roundTrip(req, do)
+	if err != nil {
+		return err
+	}
+
+	if result.StatusCode != http.StatusOK || string(result.Body) != echo {
+		return ErrEchoMismatch
+	}
+
+	return nil
+}
+
+// withCorpID 未指定接收方的事件以 Simulator 的 CorpID 为接收方
+func (s *Simulator) withCorpID(e *Event) *Event {
+	if e.ToUserName != "" {
+		return e
+	}
+
+	x := *e
+	x.ToUserName = s.corpID
+	return &x
+}
+
+func (s *Simulator) doHTTP(req *http.Request) (*http.Response, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req)
+}
+
+func serveWith(h http.Handler) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result(), nil
+	}
+}
+
+func (s *Simulator) roundTrip(
+	req *http.Request,
+	do func(*http.Request) (*http.Response, error),
+) (*DeliveryResult, error) {
+	resp, err := do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DeliveryResult{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		Reply:      nil,
+	}
+	if resp.StatusCode == http.StatusOK && req.Method == http.MethodPost {
+		result.Reply, err = s.openReply(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+// openReply 解开被动回复包；响应体不是被动回复包时返回 nil
+func (s *Simulator) openReply(body []byte) ([]byte, error) {
+	var x xmlEncryptedEnvelope
+	if xml.Unmarshal(body, &x) != nil || x.Encrypt == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(fmt.Sprintf(
+		"/?msg_signature=%s&timestamp=%d&nonce=%s",
+		url.QueryEscape(x.MsgSignature),
+		x.Timestamp,
+		url.QueryEscape(x.Nonce),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := s.ep.HandleIncomingMsg(u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return env.Msg, nil
+}
+
+// xmlEncryptedEnvelope 加密包，即被动回复包的格式
+type xmlEncryptedEnvelope struct {
+	Encrypt      string `xml:"Encrypt"`
+	MsgSignature string `xml:"MsgSignature"`
+	Timestamp    int64  `xml:"TimeStamp"`
+	Nonce        string `xml:"Nonce"`
+}
+
+func signedURL(endpoint string, params map[string]string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func makeNonce() (string, error) {
+	var b [8]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b[:]), nil
+}
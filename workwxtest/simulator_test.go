@@ -0,0 +1,204 @@
+package workwxtest
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/EnxZhou/go-workwx"
+)
+
+const (
+	testToken          = "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme"
+	testEncodingAESKey = "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"
+	testCorpID         = "ww6a112864f8022910"
+)
+
+func TestSimulator(t *testing.T) {
+	c.Convey("回调模拟器", t, func() {
+		var got []*workwx.RxMessage
+		var reply workwx.RxReply
+		h, err := workwx.NewHTTPHandler(
+			testToken,
+			testEncodingAESKey,
+			workwx.RxMessageHandlerFunc(func(msg *workwx.RxMessage) (workwx.RxReply, error) {
+				got = append(got, msg)
+				return reply, nil
+			}),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		sim, err := NewSimulator(testToken, testEncodingAESKey, testCorpID)
+		c.So(err, c.ShouldBeNil)
+
+		c.Convey("回调 URL 验证", func() {
+			c.So(sim.EchoTestTo(h), c.ShouldBeNil)
+
+			other, err := NewSimulator("QDG6eK3bOvFpBMb2hsSPKBPHXbjoDrqD", "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C", testCorpID)
+			c.So(err, c.ShouldBeNil)
+			c.So(other.EchoTestTo(h), c.ShouldEqual, ErrEchoMismatch)
+		})
+
+		c.Convey("所有样例都能被正确解析", func() {
+			for _, kind := range SupportedEventKinds() {
+				parts := append(strings.Split(kind, "/"), "", "")
+				e, err := NewEvent(
+					workwx.MessageType(parts[0]),
+					workwx.EventType(parts[1]),
+					workwx.ChangeType(parts[2]),
+				)
+				c.So(err, c.ShouldBeNil)
+				e.AgentID = 1000002
+
+				result, err := sim.DeliverTo(h, e)
+				c.So(err, c.ShouldBeNil)
+				c.So(result.StatusCode, c.ShouldEqual, 200)
+
+				msg := got[len(got)-1]
+				c.So(EventKind(msg.MsgType, msg.Event, msg.ChangeType), c.ShouldEqual, kind)
+				_, unknown := msg.EventUnknown()
+				c.So(unknown, c.ShouldBeFalse)
+			}
+			c.So(got, c.ShouldHaveLength, len(SupportedEventKinds()))
+		})
+
+		c.Convey("覆盖字段并取回被动回复", func() {
+			e, err := NewEvent(workwx.MessageTypeText, "", "")
+			c.So(err, c.ShouldBeNil)
+			e.Set("Content", "hello ]]> world")
+			reply = &workwx.TextReply{Content: "pong"}
+
+			result, err := sim.DeliverTo(h, e)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(result.Reply), c.ShouldContainSubstring, "<![CDATA[pong]]>")
+
+			x, ok := got[0].Text()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(x.GetContent(), c.ShouldEqual, "hello ]]> world")
+		})
+
+		c.Convey("通过 HTTP 推送", func() {
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			c.So(sim.EchoTest(context.Background(), srv.URL+"/callback"), c.ShouldBeNil)
+
+			e, err := NewEvent(workwx.MessageTypeEvent, workwx.EventTypeAppMenuClick, "")
+			c.So(err, c.ShouldBeNil)
+			result, err := sim.Deliver(context.Background(), srv.URL+"/callback", e)
+			c.So(err, c.ShouldBeNil)
+			c.So(result.StatusCode, c.ShouldEqual, 200)
+			c.So(got, c.ShouldHaveLength, 1)
+		})
+
+		c.Convey("不支持的类型", func() {
+			_, err := NewEvent(workwx.MessageTypeEvent, "no_such_event", "")
+			c.So(err, c.ShouldEqual, ErrUnsupportedEvent)
+		})
+	})
+}
+
+// parsedEventKinds 从 SDK 源码中找出 extractMessageExtras 能解析的所有消息、事件类型
+func parsedEventKinds(t *testing.T) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "..", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consts := make(map[string]string)
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, f := range pkgs["workwx"].Files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				funcs[d.Name.Name] = d
+			case *ast.GenDecl:
+				if d.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, name := range vs.Names {
+						if i >= len(vs.Values) {
+							continue
+						}
+						if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							consts[name.Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var result []string
+	var walk func(body ast.Node, prefix []string) bool
+	walk = func(body ast.Node, prefix []string) bool {
+		found := false
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.CallExpr:
+				// 通讯录变更事件由单独的函数解析
+				if id, ok := x.Fun.(*ast.Ident); ok && funcs[id.Name] != nil && len(prefix) > 0 {
+					found = walk(funcs[id.Name].Body, prefix) || found
+				}
+			case *ast.SwitchStmt:
+				for _, stmt := range x.Body.List {
+					cc := stmt.(*ast.CaseClause)
+					for _, e := range cc.List {
+						id, ok := e.(*ast.Ident)
+						if !ok || consts[id.Name] == "" {
+							continue
+						}
+						found = true
+						kind := append(slices.Clone(prefix), consts[id.Name])
+						if !walk(&ast.BlockStmt{List: cc.Body}, kind) {
+							result = append(result, strings.Join(kind, "/"))
+						}
+					}
+				}
+				return false
+			}
+			return true
+		})
+		return found
+	}
+	walk(funcs["extractMessageExtras"].Body, nil)
+
+	return result
+}
+
+func TestSampleCoverage(t *testing.T) {
+	c.Convey("SDK 能解析的每种消息、事件类型都有样例", t, func() {
+		kinds := parsedEventKinds(t)
+		c.So(kinds, c.ShouldContain, "text")
+		c.So(kinds, c.ShouldContain, "event/change_contact/create_user")
+
+		supported := SupportedEventKinds()
+		var missing []string
+		for _, kind := range kinds {
+			// 不区分 ChangeType 解析的事件，有任一 ChangeType 的样例即可
+			covered := slices.ContainsFunc(supported, func(s string) bool {
+				return s == kind || strings.HasPrefix(s, kind+"/")
+			})
+			if !covered {
+				missing = append(missing, kind)
+			}
+		}
+		c.So(missing, c.ShouldBeEmpty)
+	})
+}